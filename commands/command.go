@@ -18,35 +18,44 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"os/signal"
-	"path"
-	"path/filepath"
 
-	"github.com/adrg/xdg"
+	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/commands/completioncmd"
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/destroycmd"
+	"github.com/kubenet-dev/kubenetctl/commands/docscmd"
+	"github.com/kubenet-dev/kubenetctl/commands/exercisescmd"
 	"github.com/kubenet-dev/kubenetctl/commands/installcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/invcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/learncmd"
 	"github.com/kubenet-dev/kubenetctl/commands/networkbridgedcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/networkconfigcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/networkdefaultcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/networkirbcmd"
 	"github.com/kubenet-dev/kubenetctl/commands/networkroutedcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/profilecmd"
+	"github.com/kubenet-dev/kubenetctl/commands/replaycmd"
+	"github.com/kubenet-dev/kubenetctl/commands/resetcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
 	"github.com/kubenet-dev/kubenetctl/commands/sdccmd"
 	"github.com/kubenet-dev/kubenetctl/commands/setupcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/validatecmd"
+	"github.com/kubenet-dev/kubenetctl/commands/verifycmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+	"github.com/kubenet-dev/kubenetctl/pkg/release"
+	"github.com/kubenet-dev/kubenetctl/pkg/remote"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/kubenet-dev/kubenetctl/pkg/toolbox"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-const (
-	defaultConfigFileSubDir  = "kubenet"
-	defaultConfigFileName    = "kubenet"
-	defaultConfigFileNameExt = "yaml"
-	defaultConfigEnvPrefix   = "KUBENETCTL"
-	//defaultDBPath            = "package_db"
-)
-
 var (
 	configFile string
 )
@@ -54,6 +63,24 @@ var (
 func GetMain(ctx context.Context) *cobra.Command {
 	//var auto bool
 	var shell string
+	var diff bool
+	var record string
+	var profile string
+	var parallel int
+	var host string
+	var execMode string
+	var skipVerify bool
+	var withDeps bool
+	var updates <-chan string
+	var remoteClient *remote.Client
+	// the post run hooks are skipped when the command fails, the finalizers
+	// are not
+	cobra.OnFinalize(func() {
+		if remoteClient != nil {
+			remoteClient.Close()
+			remoteClient = nil
+		}
+	})
 	//showVersion := false
 	cmd := &cobra.Command{
 		Use:          "kubenet",
@@ -63,15 +90,87 @@ func GetMain(ctx context.Context) *cobra.Command {
 		// We handle all errors in main after return from cobra so we can
 		// adjust the error message coming from libraries
 		SilenceErrors: true,
+		// replaced by the completion command with dynamic values
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// initialize viper settings
+			if err := config.Init(configFile, cmd.Flags()); err != nil {
+				return err
+			}
+			p := config.CurrentProfile()
+			if err := config.ValidateProfileName(p.Name); err != nil {
+				return err
+			}
+			if err := p.Activate(); err != nil {
+				return err
+			}
+			if !viper.GetBool(config.KeyColor) {
+				color.Disable()
+			}
 			ctx := cmd.Context()
 			//ctx = context.WithValue(ctx, run.CtxKeyAutomatic, auto)
-			ctx = context.WithValue(ctx, run.CtxKeyShell, shell)
+			ctx = context.WithValue(ctx, run.CtxKeyShell, viper.GetString(config.KeyShell))
+			ctx = context.WithValue(ctx, run.CtxKeyStepDelay, viper.GetDuration(config.KeyStepDelay))
+			ctx = context.WithValue(ctx, run.CtxKeyDiff, diff)
+			ctx = context.WithValue(ctx, run.CtxKeyRecord, record)
+			ctx = context.WithValue(ctx, run.CtxKeyParallel, parallel)
+			ctx = context.WithValue(ctx, run.CtxKeySkipVerify, skipVerify)
+			ctx = context.WithValue(ctx, run.CtxKeyVerifyTimeout, config.Timeout())
+			ctx = context.WithValue(ctx, run.CtxKeyWithDeps, withDeps)
+			ctx = context.WithValue(ctx, run.CtxKeyEnvAllowlist, config.EnvAllowlist())
+			ctx = context.WithValue(ctx, run.CtxKeyRunbooks, run.Resolver(func(name string) (*run.Run, error) {
+				rb, err := runbooks.Get(name)
+				if err != nil {
+					return nil, err
+				}
+				return rb.New(), nil
+			}))
+			k, err := config.GetKey(config.KeyExecMode)
+			if err != nil {
+				return err
+			}
+			if err := k.Validate(config.ExecMode()); err != nil {
+				return err
+			}
+			if config.ExecMode() == config.ExecModeContainer {
+				if config.Host() != "" {
+					return fmt.Errorf("--exec-mode %s cannot be combined with --host", config.ExecModeContainer)
+				}
+				// the toolbox is set up when a runbook runs its first step
+				image := viper.GetString(config.KeyToolbox)
+				if image == "" {
+					image = toolbox.Image(version)
+				}
+				tb := toolbox.New(image)
+				ctx = context.WithValue(ctx, run.CtxKeyExecutor, run.Executor(tb))
+			}
+			if host := config.Host(); host != "" {
+				target, err := remote.ParseTarget(host)
+				if err != nil {
+					return err
+				}
+				remoteClient = remote.New(target, remote.Options{
+					IdentityFile: viper.GetString(config.KeyIdentity),
+					KnownHosts:   viper.GetString(config.KeyKnownHosts),
+					Kubeconfig:   p.RemoteKubeconfig(),
+				})
+				ctx = context.WithValue(ctx, run.CtxKeyExecutor, run.Executor(remoteClient))
+			}
 			cmd.SetContext(ctx)
-			initConfig()
+			if checkUpdates(cmd) {
+				checker := release.NewChecker(release.NewGitHub(config.ReleaseURL()), config.CacheFile("update-check.json"))
+				updates = checker.Start(ctx, version)
+			}
 			return nil
 		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if updates == nil {
+				return
+			}
+			if latest := <-updates; latest != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\nkubenet %s is available, you have %s; run `kubenet version upgrade` to upgrade\n", latest, release.Tag(version))
+			}
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			h, err := cmd.Flags().GetBool("help")
 			if err != nil {
@@ -86,33 +185,59 @@ func GetMain(ctx context.Context) *cobra.Command {
 	}
 
 	//pf := cmd.PersistentFlags()
-	// Catch interrupts and cleanup
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		for range c {
-			os.Exit(0)
-		}
-	}()
+	// interrupts cancel the context of the command, see main
 
-	// ensure the viper config directory exists
-	cobra.CheckErr(os.MkdirAll(path.Join(xdg.ConfigHome, defaultConfigFileSubDir), 0700))
-	// initialize viper settings
-	initConfig()
-
-	cmd.AddCommand(setupcmd.NewCommand(ctx, version))
-	cmd.AddCommand(destroycmd.NewCommand(ctx, version))
-	cmd.AddCommand(installcmd.NewCommand(ctx, version))
-	cmd.AddCommand(sdccmd.NewCommand(ctx, version))
-	cmd.AddCommand(invcmd.NewCommand(ctx, version))
-	cmd.AddCommand(networkconfigcmd.NewCommand(ctx, version))
-	cmd.AddCommand(networkdefaultcmd.NewCommand(ctx, version))
-	cmd.AddCommand(networkbridgedcmd.NewCommand(ctx, version))
-	cmd.AddCommand(networkroutedcmd.NewCommand(ctx, version))
-	cmd.AddCommand(networkirbcmd.NewCommand(ctx, version))
+	// the exercises are listed in the order they are done
+	cobra.EnableCommandSorting = false
+	cmd.AddGroup(&cobra.Group{ID: "exercises", Title: "Exercises, in order:"})
+	for _, c := range []*cobra.Command{
+		setupcmd.NewCommand(ctx, version),
+		installcmd.NewCommand(ctx, version),
+		sdccmd.NewCommand(ctx, version),
+		invcmd.NewCommand(ctx, version),
+		networkconfigcmd.NewCommand(ctx, version),
+		networkdefaultcmd.NewCommand(ctx, version),
+		networkbridgedcmd.NewCommand(ctx, version),
+		networkroutedcmd.NewCommand(ctx, version),
+		networkirbcmd.NewCommand(ctx, version),
+		destroycmd.NewCommand(ctx, version),
+	} {
+		c.GroupID = "exercises"
+		cmd.AddCommand(c)
+	}
+	cmd.AddCommand(exercisescmd.NewCommand(ctx, version))
+	cmd.AddCommand(networkcmd.NewCommand(ctx, version))
+	cmd.AddCommand(validatecmd.NewCommand(ctx, version))
+	cmd.AddCommand(verifycmd.NewCommand(ctx, version))
+	cmd.AddCommand(resetcmd.NewCommand(ctx, version))
+	cmd.AddCommand(learncmd.NewCommand(ctx, version))
+	cmd.AddCommand(docscmd.NewCommand(ctx, version))
+	cmd.AddCommand(replaycmd.NewCommand(ctx, version))
+	cmd.AddCommand(completioncmd.NewCommand(ctx, version))
+	cmd.AddCommand(configcmd.NewCommand(ctx, version))
+	cmd.AddCommand(profilecmd.NewCommand(ctx, version))
 	cmd.AddCommand(GetVersionCommand(ctx))
+	cmd.AddCommand(&cobra.Command{
+		Use:   "exit-codes",
+		Short: "exit codes of kubenet",
+		Long:  "kubenet exits with one of the following codes, so scripts can tell failures apart.\n\n" + exitcode.Table(),
+	})
 	//cmd.PersistentFlags().BoolVarP(&auto, "interactive", "i", true, "run in interacti mode")
-	cmd.PersistentFlags().StringVar(&shell, "shell", "bash", "shell to be used to execute the commands")
+	cmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("config file (default %s)", config.DefaultFile()))
+	cmd.PersistentFlags().StringVar(&shell, config.KeyShell, "bash", "shell to be used to execute the commands")
+	cmd.PersistentFlags().StringVar(&profile, config.KeyProfile, config.DefaultProfile, "profile of the lab, to run several labs side by side")
+	cmd.PersistentFlags().StringVar(&host, config.KeyHost, "", "run the steps and checks of the runbooks on this host over SSH, user@host[:port]")
+	cmd.PersistentFlags().StringVar(&execMode, config.KeyExecMode, config.ExecModeHost, "where the steps run: host, or container to run them in the kubenet toolbox image")
+	cmd.PersistentFlags().BoolVar(&diff, "diff", false, "show what the commands would change instead of applying them")
+	cmd.PersistentFlags().StringVar(&record, "record", "", "record the session as an asciicast v2 file")
+	cmd.PersistentFlags().BoolVar(&skipVerify, "skip-verify", false, "do not run the verification checks after the steps of an exercise")
+	cmd.PersistentFlags().BoolVar(&withDeps, "with-deps", false, "run the exercises an exercise requires first when they are not done")
+	cmd.PersistentFlags().IntVar(&parallel, "parallel", run.DefaultParallel, "maximum number of steps of a parallel group that run at the same time")
+	_ = cmd.RegisterFlagCompletionFunc(config.KeyExecMode, completion.Values(config.ExecModeHost, config.ExecModeContainer))
+	_ = cmd.RegisterFlagCompletionFunc("shell", completion.Values("bash", "sh", "zsh"))
+	_ = cmd.MarkPersistentFlagFilename("record", "cast")
+	_ = cmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+	_ = cmd.RegisterFlagCompletionFunc(config.KeyProfile, completion.Profiles)
 
 	return cmd
 }
@@ -122,28 +247,16 @@ type Runner struct {
 	//Ctx     context.Context
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if configFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(configFile)
-	} else {
-
-		viper.AddConfigPath(filepath.Join(xdg.ConfigHome, defaultConfigFileSubDir))
-		viper.SetConfigType(defaultConfigFileNameExt)
-		viper.SetConfigName(defaultConfigFileName)
-
-		_ = viper.SafeWriteConfig()
+// checkUpdates returns true when the latest release is looked up for the
+// command. Development builds, scripts and the shell completion are not
+// bothered with it.
+func checkUpdates(cmd *cobra.Command) bool {
+	if !config.UpdateCheck() || release.Compare(version, "0.0.0") == 0 || !prompt.IsTerminal(os.Stderr) {
+		return false
 	}
-
-	//viper.Set("kubecontext", kubecontext)
-	//viper.Set("kubeconfig", kubeconfig)
-
-	viper.SetEnvPrefix(defaultConfigEnvPrefix)
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		_ = 1
+	switch cmd.Name() {
+	case "upgrade", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
 	}
+	return true
 }
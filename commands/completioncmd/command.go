@@ -0,0 +1,90 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completioncmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "generate the shell completion script",
+		Long: `Generate the shell completion script.
+
+Besides the commands and flags, networks, namespaces, topologies and
+interfaces are completed with the values in the cluster, exercises with the
+catalog and replay with the recordings made with --record. Kubeconfig
+contexts are not completed, the profile selects the kubeconfig of a lab.
+
+Bash:
+  source <(kubenet completion bash)
+  # load the completions for each session
+  kubenet completion bash > /etc/bash_completion.d/kubenet
+
+Zsh:
+  kubenet completion zsh > "${fpath[1]}/_kubenet"
+
+Fish:
+  kubenet completion fish > ~/.config/fish/completions/kubenet.fish
+
+PowerShell:
+  kubenet completion powershell | Out-String | Invoke-Expression`,
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		PreRunE:               r.preRunE,
+		RunE:                  r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	root := c.Root()
+	out := c.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return root.GenBashCompletionV2(out, true)
+	case "zsh":
+		return root.GenZshCompletion(out)
+	case "fish":
+		return root.GenFishCompletion(out, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell %q", args[0])
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcmd
+
+import (
+	"context"
+
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd/editcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd/getcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd/setcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd/unsetcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/configcmd/viewcmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "view and change the kubenet settings",
+		Long:  "View and change the kubenet settings.\n\n" + config.Describe(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(viewcmd.NewCommand(ctx, version))
+	cmd.AddCommand(getcmd.NewCommand(ctx, version))
+	cmd.AddCommand(setcmd.NewCommand(ctx, version))
+	cmd.AddCommand(unsetcmd.NewCommand(ctx, version))
+	cmd.AddCommand(editcmd.NewCommand(ctx, version))
+	return cmd
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package editcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "edit [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "edit the config file with $VISUAL or $EDITOR",
+		Long: `Edit the config file with $VISUAL or $EDITOR.
+
+A copy of the config file is edited and only written back when the keys and
+values are valid.`,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	path := config.FileUsed()
+	current, err := config.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "kubenet-config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	tmp.Close()
+	current.Path = tmp.Name()
+	if err := current.Write(); err != nil {
+		return err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.CommandContext(c.Context(), "sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	edited, err := config.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if err := edited.Validate(); err != nil {
+		return fmt.Errorf("%w\nthe config file %s is unchanged", err, path)
+	}
+	edited.Path = path
+	if err := edited.Write(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "saved %s\n", path)
+	return nil
+}
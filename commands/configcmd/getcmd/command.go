@@ -0,0 +1,61 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "get KEY [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "print the effective value of a setting",
+		ValidArgsFunction: completion.Values(config.KeyNames()...),
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, args []string) error {
+	_, err := config.GetKey(args[0])
+	return err
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	_, err := fmt.Fprintln(c.OutOrStdout(), config.Get(args[0]))
+	return err
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:     "set KEY VALUE [flags]",
+		Args:    cobra.ExactArgs(2),
+		Short:   "set a value in the config file",
+		Example: "  kubenet config set kubenet-ref v0.0.2\n  kubenet config set color false",
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completion.Values(config.KeyNames()...)(cmd, args, toComplete)
+			}
+			if k, err := config.GetKey(args[0]); err == nil && len(k.Enum) > 0 {
+				return completion.Values(k.Enum...)(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, args []string) error {
+	k, err := config.GetKey(args[0])
+	if err != nil {
+		return err
+	}
+	return k.Validate(args[1])
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	f, err := config.ReadFile(config.FileUsed())
+	if err != nil {
+		return err
+	}
+	f.Settings[args[0]] = args[1]
+	if err := f.Write(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "set %s to %q in %s\n", args[0], args[1], f.Path)
+	if source := config.SourceOf(args[0], c.Flags()); source == config.SourceEnv {
+		k, _ := config.GetKey(args[0])
+		fmt.Fprintf(c.OutOrStdout(), "note: %s overrides the config file\n", k.Env())
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package viewcmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:     "view [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   "show the effective settings and where each value comes from",
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	fmt.Fprintf(c.OutOrStdout(), "config file: %s\n\n", config.FileUsed())
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, k := range config.Keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", k.Name, config.Get(k.Name), config.SourceOf(k.Name, c.Flags()))
+	}
+	return w.Flush()
+}
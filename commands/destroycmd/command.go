@@ -18,13 +18,17 @@ package destroycmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "delete the kind cluster and the containerlab topology"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +37,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "destroy [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "destroy [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,22 +63,53 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Destroy kubenet Environment")
+	if err := Runbook().Run(ctx); err != nil {
+		return err
+	}
+	if diff, _ := c.Flags().GetBool("diff"); diff {
+		return nil
+	}
+	// the exercises are undone with the cluster and the lab
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	prog.Reset()
+	return prog.Save()
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	p := config.CurrentProfile()
+	clab := "sudo containerlab destroy -t " + config.KubenetURL("lab/3node.yaml")
+	if !p.IsDefault() {
+		clab += " --name " + p.LabName()
+	}
+
+	x := run.NewRun("Destroy kubenet Environment",
+		"Deletes the kind cluster and the containerlab topology and removes the iptables rule that connects them.",
+	)
+	x.SetName("destroy")
 
 	x.Step(
 		run.S("Drop the iptables rule"),
-		run.S("sudo iptables -D DOCKER-USER -o br-$(docker network inspect -f '{{ printf \"%.12s\" .ID }}' kind) -j ACCEPT"),
+		run.S("sudo iptables -D DOCKER-USER -o br-$(docker network inspect -f '{{ printf \"%.12s\" .ID }}' "+p.DockerNetwork()+") -j ACCEPT"),
+		run.Plan("would delete iptables rule in the DOCKER-USER chain accepting traffic to the %s network", p.DockerNetwork()),
+		run.HostOnly(),
 	)
 
 	x.Step(
 		run.S("Delete the kind cluster"),
-		run.S("kind delete cluster --name kubenet"),
+		run.S("kind delete cluster --name "+p.ClusterName()),
+		run.Plan("would delete kind cluster %s", p.ClusterName()),
+		run.Expect("`kind get clusters` no longer lists %s", p.ClusterName()),
 	)
 
 	x.Step(
 		run.S("Destroy Containerlab topology"),
-		run.S("sudo containerlab destroy -t https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/lab/3node.yaml"),
+		run.S(clab),
+		run.Plan("would destroy containerlab topology 3node"),
 	)
 
-	return x.Run(ctx)
+	return x
 }
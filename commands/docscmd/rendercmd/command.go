@@ -0,0 +1,124 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendercmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/docs"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "render [RUNBOOK] [flags]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "render a runbook as a markdown or html lab guide",
+		Example: `  kubenet docs render networkbridged --format md
+  kubenet docs render --all --format html --output-dir site`,
+		ValidArgs: runbooks.Names(),
+		PreRunE:   r.preRunE,
+		RunE:      r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVar(&r.format, "format", string(docs.FormatMarkdown), "output format, one of md or html")
+	cmd.Flags().BoolVar(&r.all, "all", false, "render all runbooks and a site index to the output directory")
+	cmd.Flags().StringVar(&r.outputDir, "output-dir", "docs", "directory the guides are written to with --all")
+	_ = cmd.RegisterFlagCompletionFunc("format", completion.Values(string(docs.FormatMarkdown), string(docs.FormatHTML)))
+	_ = cmd.MarkFlagDirname("output-dir")
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	format    string
+	all       bool
+	outputDir string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, args []string) error {
+	if r.all && len(args) > 0 {
+		return fmt.Errorf("a runbook cannot be combined with --all")
+	}
+	if !r.all && len(args) == 0 {
+		return fmt.Errorf("a runbook or --all is required, runbooks: %v", runbooks.Names())
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	format, err := docs.ParseFormat(r.format)
+	if err != nil {
+		return err
+	}
+
+	if !r.all {
+		rb, err := runbooks.Get(args[0])
+		if err != nil {
+			return err
+		}
+		return docs.Render(c.OutOrStdout(), format, rb.New())
+	}
+
+	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
+		return err
+	}
+	pages := []docs.Page{}
+	for _, rb := range runbooks.All {
+		x := rb.New()
+		page := docs.Page{Name: rb.Name, Title: x.Title(), File: rb.Name + format.Ext()}
+		if err := renderFile(filepath.Join(r.outputDir, page.File), func(f *os.File) error {
+			return docs.Render(f, format, x)
+		}); err != nil {
+			return err
+		}
+		pages = append(pages, page)
+	}
+	index := filepath.Join(r.outputDir, "index"+format.Ext())
+	if err := renderFile(index, func(f *os.File) error {
+		return docs.RenderIndex(f, format, pages)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "rendered %d runbooks and %s\n", len(pages), index)
+	return nil
+}
+
+func renderFile(path string, render func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot render %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "describe EXERCISE [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "show the description, steps and resources of an exercise",
+		ValidArgsFunction: completion.Values(runbooks.Names()...),
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	rb, err := runbooks.Get(args[0])
+	if err != nil {
+		return err
+	}
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	x := rb.New()
+	out := c.OutOrStdout()
+
+	fmt.Fprintf(out, "Name:      %s\n", rb.Name)
+	fmt.Fprintf(out, "Title:     %s\n", x.Title())
+	fmt.Fprintf(out, "Time:      %s\n", rb.EstimatedTime())
+	fmt.Fprintf(out, "Status:    %s\n", prog.Status(rb.Name))
+	fmt.Fprintf(out, "Requires:  %s\n", orNone(strings.Join(rb.Requires(), ", ")))
+	for _, d := range x.Description() {
+		fmt.Fprintf(out, "\n%s\n", d)
+	}
+
+	fmt.Fprintf(out, "\nSteps:\n")
+	for i, s := range x.Steps() {
+		host := ""
+		if s.HostOnly {
+			host = " (runs on the host)"
+		}
+		fmt.Fprintf(out, "  %d. %s%s\n", i+1, strings.Join(s.Text, " "), host)
+		fmt.Fprintf(out, "     %s\n", strings.Join(s.Command, " "))
+	}
+	needs := []string{}
+	for _, req := range x.Requirements() {
+		if req.Text != "" {
+			needs = append(needs, fmt.Sprintf("%s (from %s)", req.Text, req.Exercise))
+		}
+	}
+	list(out, "Needs", needs)
+	list(out, "Creates", runbooks.Creates(x))
+	checks := []string{}
+	for _, ch := range x.Checks() {
+		checks = append(checks, strings.Join(ch.Text, " "))
+	}
+	list(out, "Checks", checks)
+	return nil
+}
+
+func list(out io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(out, "  - %s\n", item)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:     "list [flags]",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		Short:   "list the exercises with their prerequisites, estimated time and status",
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+
+	cmd.Flags().StringVarP(&r.output, "output", "o", "", "output format, one of text, json or yaml (defaults to the configured output)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.Values("text", "json", "yaml"))
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+	output  string
+}
+
+type exercise struct {
+	Name     string   `json:"name" yaml:"name"`
+	Summary  string   `json:"summary" yaml:"summary"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Estimate string   `json:"estimate" yaml:"estimate"`
+	Status   string   `json:"status" yaml:"status"`
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.output == "" {
+		r.output = config.Output()
+	}
+	k, _ := config.GetKey(config.KeyOutput)
+	return k.Validate(r.output)
+}
+
+func (r *Runner) runE(c *cobra.Command, _ []string) error {
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	exercises := make([]exercise, 0, len(runbooks.All))
+	for _, rb := range runbooks.All {
+		exercises = append(exercises, exercise{
+			Name:     rb.Name,
+			Summary:  rb.Summary,
+			Requires: rb.Requires(),
+			Estimate: rb.EstimatedTime(),
+			Status:   prog.Status(rb.Name),
+		})
+	}
+
+	switch r.output {
+	case "json":
+		enc := json.NewEncoder(c.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(exercises)
+	case "yaml":
+		enc := yaml.NewEncoder(c.OutOrStdout())
+		enc.SetIndent(2)
+		if err := enc.Encode(exercises); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSUMMARY\tREQUIRES\tTIME\tSTATUS")
+	for _, e := range exercises {
+		requires := strings.Join(e.Requires, ",")
+		if requires == "" {
+			requires = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.Summary, requires, e.Estimate, e.Status)
+	}
+	return w.Flush()
+}
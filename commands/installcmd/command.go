@@ -18,13 +18,17 @@ package installcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "install the kubenet components in the cluster"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +37,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "install [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "install [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,32 +63,54 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Install kubenet Components")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Install kubenet Components",
+		"Installs the package server, sdc, kuid-server, kuid-apps and the kuid-nokia-srl vendor app in the kind cluster.",
+	)
+	x.SetName("install")
+	x.Require(
+		run.RequireExercise("setup"),
+	)
 
 	x.Step(
 		run.S("install package server: (tool to interact with git from k8s using packages (KRM manifests))"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/artifacts/out/pkgserver.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("artifacts/out/pkgserver.yaml")),
+		run.Group("components"),
 	)
 
 	x.Step(
 		run.S("install sdc: (tool to interact with yang devices from k8s)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/artifacts/out/sdc.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("artifacts/out/sdc.yaml")),
+		run.Group("components"),
 	)
 
 	x.Step(
 		run.S("install kuid-server: (tool for inventory and identity (IPAM/VLAN/AS/etc) using k8s api"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/artifacts/out/kuid-server.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("artifacts/out/kuid-server.yaml")),
+		run.Group("components"),
 	)
 
 	x.Step(
 		run.S("install kuid-apps: (apps leveraging kuid-server focussed on networking"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/artifacts/out/kuidapps.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("artifacts/out/kuidapps.yaml")),
+		run.Group("components"),
 	)
 
 	x.Step(
 		run.S("install kuid-nokia-srl: (vendor specific app for specific nokia srl artifacts "),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/artifacts/out/kuid-nokia-srl.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("artifacts/out/kuid-nokia-srl.yaml")),
+		run.Expect("`kubectl get pods -A` shows the pods of all kubenet components Running"),
+		run.Group("components"),
+	)
+
+	x.Check(
+		run.S("the kubenet components are available"),
+		run.S(checks.DeploymentsAvailable()),
 	)
 
-	return x.Run(ctx)
+	return x
 }
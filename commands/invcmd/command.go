@@ -18,13 +18,17 @@ package invcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "import the node models and the topology in the inventory"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +37,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "inventory [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "inventory [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,22 +63,40 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue the topology inventory")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue the topology inventory",
+		"Applies the node models of the ixrd2 and ixrd3 SR Linux devices and imports the containerlab topology in the kuid inventory.",
+	)
+	x.SetName("inventory")
+	x.Require(
+		run.RequireExercise("install"),
+		run.RequireResource("topologies.topo.kuid.dev", "install"),
+	)
 
 	x.Step(
 		run.S("apply the nodemodel configuration for ixrd2 srlinux device"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/inventory/srl/ixrd2.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("inventory/srl/ixrd2.yaml")),
 	)
 
 	x.Step(
 		run.S("apply the nodemodel configuration for ixrd3 srlinux device"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/inventory/srl/ixrd3.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("inventory/srl/ixrd3.yaml")),
 	)
 
 	x.Step(
 		run.S("import the containerlab topology in kubernetes"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/topo/3node-topology.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("topo/3node-topology.yaml")),
+		run.Expect("`kubectl get nodes.infra.kuid.dev` lists the nodes of the topology"),
+	)
+
+	x.Check(
+		run.S("the nodes of the topology are in the inventory"),
+		run.S(checks.Exists("nodes.infra.kuid.dev")),
 	)
 
-	return x.Run(ctx)
+	return x
 }
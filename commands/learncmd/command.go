@@ -0,0 +1,282 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package learncmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "learn [EXERCISE] [flags]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "do an exercise yourself, with hints and a check of your work",
+		Long: `Do an exercise yourself.
+
+kubenet learn shows the objective of the exercise and opens a shell in which
+you apply your own manifests. When you are done, the checks of the exercise
+verify your work. Hints reveal the steps of the exercise one at a time; the
+reference solution is only shown on request.
+
+Without an exercise, kubenet learn shows your progress.`,
+		Example:           "  kubenet learn networkbridged\n  kubenet learn",
+		ValidArgsFunction: completion.Values(runbooks.Names()...),
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	cmd.Flags().DurationVar(&r.timeout, "timeout", time.Minute, "how long the checks are retried when you are done")
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+	timeout time.Duration
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return r.list(c.OutOrStdout(), prog)
+	}
+
+	rb, err := runbooks.Get(args[0])
+	if err != nil {
+		return err
+	}
+	x := rb.New()
+	if !x.HasChecks() {
+		return fmt.Errorf("exercise %s has no checks to verify your work, run `kubenet %s` instead", rb.Name, rb.Name)
+	}
+	if !prompt.IsTerminal(os.Stdin) {
+		return exitcode.Preconditionf("kubenet learn is interactive and needs a terminal")
+	}
+
+	e := prog.Get(rb.Name)
+	if err := prog.Save(); err != nil {
+		return err
+	}
+	s := &session{
+		name: rb.Name,
+		run:  x,
+		prog: prog,
+		e:    e,
+		out:  c.OutOrStdout(),
+		p:    prompt.New(c.InOrStdin(), c.OutOrStdout()),
+	}
+	// an interrupt in the subshell must not end the session, it ends with
+	// quit or end of input
+	return s.loop(context.WithoutCancel(c.Context()), r.timeout)
+}
+
+func (r *Runner) list(out io.Writer, prog *progress.Progress) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "EXERCISE\tSTATUS\tHINTS\tSOLUTION VIEWED")
+	for _, rb := range runbooks.All {
+		if !rb.New().HasChecks() {
+			continue
+		}
+		hints, solution := "-", "-"
+		if e, ok := prog.Exercises[rb.Name]; ok {
+			hints = fmt.Sprintf("%d", e.HintsRevealed)
+			solution = fmt.Sprintf("%t", e.SolutionViewed)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rb.Name, prog.Status(rb.Name), hints, solution)
+	}
+	return w.Flush()
+}
+
+type session struct {
+	name string
+	run  *run.Run
+	prog *progress.Progress
+	e    *progress.Exercise
+	out  io.Writer
+	p    *prompt.Prompter
+}
+
+func (r *session) loop(ctx context.Context, timeout time.Duration) error {
+	r.objective()
+	for i := 0; i < r.e.HintsRevealed && i < len(r.run.Steps()); i++ {
+		r.hint(i)
+	}
+	for {
+		choice, err := r.p.String("\n[s]hell  [h]int  [d]one  s[o]lution  [q]uit", "s")
+		if err != nil {
+			// end of input
+			return nil
+		}
+		switch strings.ToLower(choice) {
+		case "s", "shell":
+			if err := r.shell(ctx); err != nil {
+				fmt.Fprintf(r.out, "shell: %s\n", err)
+			}
+		case "h", "hint":
+			if r.e.HintsRevealed >= len(r.run.Steps()) {
+				fmt.Fprintln(r.out, "no more hints, the solution shows the commands")
+				continue
+			}
+			r.hint(r.e.HintsRevealed)
+			r.e.HintsRevealed++
+			if err := r.prog.Save(); err != nil {
+				return err
+			}
+		case "d", "done":
+			done, err := r.verify(ctx, timeout)
+			if err != nil || done {
+				return err
+			}
+		case "o", "solution":
+			ok, err := r.p.Confirm("show the reference solution?", false)
+			if err != nil || !ok {
+				continue
+			}
+			r.solution()
+			r.e.SolutionViewed = true
+			if err := r.prog.Save(); err != nil {
+				return err
+			}
+		case "q", "quit":
+			fmt.Fprintf(r.out, "your progress is saved, continue with `kubenet learn %s`\n", r.name)
+			return nil
+		default:
+			fmt.Fprintf(r.out, "unknown choice %q\n", choice)
+		}
+	}
+}
+
+func (r *session) objective() {
+	fmt.Fprintln(r.out, color.Cyan.Sprintf("%s", r.run.Title()))
+	for _, d := range r.run.Description() {
+		fmt.Fprintln(r.out, d)
+	}
+	fmt.Fprintln(r.out, "\nYou are done when:")
+	for _, c := range r.run.Checks() {
+		fmt.Fprintf(r.out, "  - %s\n", strings.Join(c.Text, " "))
+	}
+	fmt.Fprintf(r.out, "\nThe exercise has %d step(s). Open a shell to do them, ask for a hint when you are stuck.\n", len(r.run.Steps()))
+}
+
+// hint reveals what step i does, without its command.
+func (r *session) hint(i int) {
+	steps := r.run.Steps()
+	s := steps[i]
+	fmt.Fprintln(r.out, color.Yellow.Sprintf("hint %d/%d: %s", i+1, len(steps), strings.Join(s.Text, " ")))
+	if s.Expect != "" {
+		fmt.Fprintf(r.out, "  expected outcome: %s\n", s.Expect)
+	}
+}
+
+func (r *session) solution() {
+	for i, s := range r.run.Steps() {
+		fmt.Fprintf(r.out, "# step %d: %s\n", i+1, strings.Join(s.Text, " "))
+		fmt.Fprintln(r.out, color.Green.Sprintf("%s", strings.Join(s.Command, " ")))
+	}
+	fmt.Fprintf(r.out, "\nrun `kubenet %s` to apply the solution\n", r.name)
+}
+
+// shell opens an interactive shell for the student to work in.
+func (r *session) shell(ctx context.Context) error {
+	sh := os.Getenv("SHELL")
+	if sh == "" {
+		sh = viper.GetString(config.KeyShell)
+	}
+	fmt.Fprintf(r.out, "opening %s, type exit to return to the exercise\n", sh)
+	cmd := exec.CommandContext(ctx, sh)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "KUBENET_EXERCISE="+r.name)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// the exit code of the last command in the shell
+		return nil
+	}
+	return err
+}
+
+// verify runs the checks of the exercise and returns true when they pass.
+func (r *session) verify(ctx context.Context, timeout time.Duration) (bool, error) {
+	r.e.Attempts++
+	fmt.Fprintf(r.out, "checking your work, for up to %s\n", timeout)
+	report, err := r.run.Verify(ctx, run.VerifyOptions{Timeout: timeout, Shell: viper.GetString(config.KeyShell)})
+	if werr := report.Write(r.out, !color.Enable); werr != nil {
+		return false, werr
+	}
+	var verifyErr *exitcode.VerifyError
+	if err != nil && !errors.As(err, &verifyErr) {
+		return false, err
+	}
+	if report.Passed {
+		r.e.Complete()
+	}
+	if err := r.prog.Save(); err != nil {
+		return false, err
+	}
+	if !report.Passed {
+		fmt.Fprintln(r.out, "not done yet, have a look at the failed checks")
+		return false, nil
+	}
+	fmt.Fprintln(r.out, color.Green.Sprintf("well done, you completed %s", r.name))
+	if next := nextExercise(r.name); next != "" {
+		fmt.Fprintf(r.out, "next: kubenet learn %s\n", next)
+	}
+	return true, nil
+}
+
+func nextExercise(name string) string {
+	for i, rb := range runbooks.All {
+		if rb.Name != name {
+			continue
+		}
+		for _, next := range runbooks.All[i+1:] {
+			if next.New().HasChecks() {
+				return next.Name
+			}
+		}
+	}
+	return ""
+}
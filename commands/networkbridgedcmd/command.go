@@ -18,13 +18,18 @@ package networkbridgedcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "configure a bridged EVPN overlay network"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +38,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "networkbridged [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "networkbridged [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,12 +64,46 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue a bridged EVPN overlay network")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue a bridged EVPN overlay network",
+		"Creates network vpc1, a layer 2 EVPN overlay that bridges the client interfaces over the underlay.",
+	)
+	x.SetName("networkbridged")
+	x.Require(
+		run.RequireExercise("networkdefault"),
+		run.RequireObject(network.Resource, "default", config.Topology()+".default", "networkdefault"),
+	)
 
 	x.Step(
 		run.S("apply the default network config"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/network/vpc1-bridged-network.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("network/vpc1-bridged-network.yaml")),
+		run.Expect("`kubenet network describe vpc1` shows the network Ready"),
+	)
+
+	x.Check(
+		run.S("network vpc1 is Ready"),
+		run.S(checks.NetworkReady(config.Topology(), "vpc1")),
+	)
+
+	x.Check(
+		run.S("the sdc config intents are applied on each target"),
+		run.S(checks.Ready("configs.config.sdcio.dev")),
+	)
+
+	x.Check(
+		run.S("the BGP EVPN sessions are established on the SR Linux nodes"),
+		run.S(checks.BGPEstablished(config.CurrentProfile().LabName())),
+	)
+
+	// the lab material configures client2 with 10.0.0.2 in vpc1
+	x.Check(
+		run.S("client1 reaches client2 over the bridged overlay"),
+		run.S(checks.Ping(config.CurrentProfile().LabName(), "client1", "10.0.0.2")),
 	)
 
-	return x.Run(ctx)
+	return x
 }
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkcmd
+
+import (
+	"context"
+
+	"github.com/kubenet-dev/kubenetctl/commands/networkcmd/createcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkcmd/deletecmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkcmd/describecmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkcmd/listcmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "manage the overlay networks of the kubenet topology",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(createcmd.NewCommand(ctx, version))
+	cmd.AddCommand(listcmd.NewCommand(ctx, version))
+	cmd.AddCommand(describecmd.NewCommand(ctx, version))
+	cmd.AddCommand(deletecmd.NewCommand(ctx, version))
+	return cmd
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package createcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "create NAME [flags]",
+		Args:  cobra.ExactArgs(1),
+		Short: "generate a bridged, routed or irb network and apply it",
+		Example: `  kubenet network create vpc1 --type bridged --network-id 10 --interface edge01:e1-1 --interface edge02:e1-1
+  kubenet network create vpc2 --type routed --network-id 100 --prefix 10.0.0.0/24 --interface edge01:e1-1:100 --dry-run`,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVar(&r.networkType, "type", "", fmt.Sprintf("network type, one of %v", network.Types))
+	cmd.Flags().StringVar(&r.topology, "topology", "", "topology the network is created in (defaults to the configured topology)")
+	cmd.Flags().StringVarP(&r.namespace, "namespace", "n", network.DefaultNamespace, "namespace of the network resource")
+	cmd.Flags().IntVar(&r.networkID, "network-id", 0, "network id, used for the vni and the default vlan")
+	cmd.Flags().IntVar(&r.vlan, "vlan", 0, "default vlan of the interfaces (defaults to the network id)")
+	cmd.Flags().StringSliceVar(&r.interfaces, "interface", nil, "attachment interface as node:endpoint[:vlan], can be repeated")
+	cmd.Flags().StringSliceVar(&r.prefixes, "prefix", nil, "ip prefix of a routed or irb network, can be repeated")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false, "print the network resource instead of applying it")
+	cmd.Flags().BoolVar(&r.skipValidation, "skip-validation", false, "do not validate the network against the topology in the cluster")
+	_ = cmd.RegisterFlagCompletionFunc("type", completion.Values(string(network.TypeBridged), string(network.TypeRouted), string(network.TypeIRB)))
+	_ = cmd.RegisterFlagCompletionFunc("topology", completion.Topologies)
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	_ = cmd.RegisterFlagCompletionFunc("interface", completion.Interfaces)
+
+	return r
+}
+
+type Runner struct {
+	Command        *cobra.Command
+	networkType    string
+	topology       string
+	namespace      string
+	networkID      int
+	vlan           int
+	interfaces     []string
+	prefixes       []string
+	dryRun         bool
+	skipValidation bool
+}
+
+func (r *Runner) preRunE(c *cobra.Command, _ []string) error {
+	if r.topology == "" {
+		r.topology = config.Topology()
+	}
+	if r.networkType == "" && !prompt.IsTerminal(os.Stdin) {
+		return fmt.Errorf("--type is required")
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	cfg := &network.Config{
+		Name:      args[0],
+		Namespace: r.namespace,
+		Type:      network.Type(r.networkType),
+		Topology:  r.topology,
+		Region:    network.DefaultRegion,
+		Site:      network.DefaultSite,
+		NetworkID: r.networkID,
+		Prefixes:  r.prefixes,
+	}
+
+	var topo *network.Topology
+	if !r.skipValidation {
+		var err error
+		topo, err = network.GetTopology(ctx, r.topology)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(r.interfaces) == 0 && prompt.IsTerminal(os.Stdin) {
+		if err := r.ask(prompt.New(os.Stdin, c.OutOrStdout()), cfg, topo); err != nil {
+			return err
+		}
+	} else {
+		vlan := r.vlan
+		if vlan == 0 {
+			vlan = r.networkID
+		}
+		for _, s := range r.interfaces {
+			a, err := network.ParseAttachment(s, vlan)
+			if err != nil {
+				return err
+			}
+			cfg.Attachments = append(cfg.Attachments, a)
+		}
+	}
+
+	n, err := network.New(cfg)
+	if err != nil {
+		return err
+	}
+	if topo != nil {
+		if err := topo.Validate(n); err != nil {
+			return err
+		}
+	}
+	b, err := n.YAML()
+	if err != nil {
+		return err
+	}
+
+	if r.dryRun {
+		_, err := c.OutOrStdout().Write(b)
+		return err
+	}
+
+	manifest, err := kubectl.NewManifest(b)
+	if err != nil {
+		return err
+	}
+
+	x := run.NewRun(fmt.Sprintf("Configure the %s network %s", cfg.Type, n.Metadata.Name))
+	x.OnSetup(manifest.Write)
+	x.OnCleanup(manifest.Remove)
+
+	x.Step(
+		run.S(fmt.Sprintf("apply the generated %s network config", cfg.Type)),
+		run.S("kubectl apply -f "+manifest.Path),
+	)
+
+	return x.Run(ctx)
+}
+
+// ask collects the parameters that were not provided as flags.
+func (r *Runner) ask(p *prompt.Prompter, cfg *network.Config, topo *network.Topology) error {
+	var err error
+	if cfg.Type == "" {
+		for {
+			s, err := p.String(fmt.Sprintf("network type %v", network.Types), string(network.TypeBridged))
+			if err != nil {
+				return err
+			}
+			if cfg.Type, err = network.ParseType(s); err == nil {
+				break
+			}
+			fmt.Fprintln(r.Command.OutOrStdout(), err)
+		}
+	}
+	if cfg.NetworkID == 0 {
+		if cfg.NetworkID, err = p.Int("network id", 10); err != nil {
+			return err
+		}
+	}
+	defaultVLAN := r.vlan
+	if defaultVLAN == 0 {
+		defaultVLAN = cfg.NetworkID
+	}
+
+	if topo != nil {
+		fmt.Fprintf(r.Command.OutOrStdout(), "nodes in topology %s: %v\n", topo.Name, topo.NodeNames())
+	}
+	nodes, err := p.List("attachment nodes (comma separated)", nil)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		eps, err := p.List(fmt.Sprintf("endpoints on %s (comma separated)", node), nil)
+		if err != nil {
+			return err
+		}
+		for _, ep := range eps {
+			vlan, err := p.Int(fmt.Sprintf("vlan on %s:%s", node, ep), defaultVLAN)
+			if err != nil {
+				return err
+			}
+			cfg.Attachments = append(cfg.Attachments, network.Attachment{Node: node, Endpoint: ep, VLANID: vlan})
+		}
+	}
+
+	if (cfg.Type == network.TypeRouted || cfg.Type == network.TypeIRB) && len(cfg.Prefixes) == 0 {
+		if cfg.Prefixes, err = p.List("prefixes (comma separated)", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
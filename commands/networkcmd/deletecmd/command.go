@@ -0,0 +1,89 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletecmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "delete NAME [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "delete a network and wait until its config is removed from the devices",
+		ValidArgsFunction: completion.Networks,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVarP(&r.namespace, "namespace", "n", network.DefaultNamespace, "namespace of the network")
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0, "time to wait for the device config to be removed (defaults to the configured timeout)")
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	namespace string
+	timeout   time.Duration
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.timeout == 0 {
+		r.timeout = config.Timeout()
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	n, err := network.Get(ctx, r.namespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	x := run.NewRun(fmt.Sprintf("Delete the %s network %s", n.Type(), n.Metadata.Name))
+
+	x.Step(
+		run.S(
+			"delete the network, the foreground cascade waits until the derived",
+			"network device configs are removed from the devices by sdc",
+		),
+		run.S(fmt.Sprintf("kubectl delete %s %s --namespace %s --cascade=foreground --wait",
+			network.Resource, n.Metadata.Name, n.Metadata.Namespace)),
+		run.Timeout(r.timeout),
+	)
+
+	return x.Run(ctx)
+}
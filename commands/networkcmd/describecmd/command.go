@@ -0,0 +1,109 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describecmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "describe NAME [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "show the status, resolved resources and devices of a network",
+		ValidArgsFunction: completion.Networks,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVarP(&r.namespace, "namespace", "n", network.DefaultNamespace, "namespace of the network")
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	namespace string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	n, err := network.Get(ctx, r.namespace, args[0])
+	if err != nil {
+		return err
+	}
+	claims, err := network.GetClaims(ctx, n)
+	if err != nil {
+		return err
+	}
+	devices, err := network.GetDevices(ctx, n)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", n.Metadata.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", n.Metadata.Namespace)
+	fmt.Fprintf(w, "Topology:\t%s\n", n.Spec.Topology)
+	fmt.Fprintf(w, "Type:\t%s\n", n.Type())
+	for _, bd := range n.Spec.BridgeDomains {
+		fmt.Fprintf(w, "Bridge domain:\t%s (%d interfaces)\n", bd.Name, len(bd.Interfaces))
+	}
+	for _, rt := range n.Spec.RoutingTables {
+		fmt.Fprintf(w, "Routing table:\t%s (%d interfaces, %d prefixes)\n", rt.Name, len(rt.Interfaces), len(rt.Prefixes))
+	}
+
+	fmt.Fprintln(w, "\nConditions:")
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+	if n.Status != nil {
+		for _, cond := range n.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+	}
+
+	fmt.Fprintln(w, "\nResolved resources:")
+	fmt.Fprintln(w, "  KIND\tNAME\tVALUE")
+	for _, claim := range claims {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", claim.Kind, claim.Name, claim.Value)
+	}
+
+	fmt.Fprintln(w, "\nDevices:")
+	fmt.Fprintln(w, "  NAME\tREADY")
+	for _, d := range devices {
+		fmt.Fprintf(w, "  %s\t%s\n", d.Name, d.Ready)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:     "list [flags]",
+		Aliases: []string{"ls"},
+		Args:    cobra.ExactArgs(0),
+		Short:   "list the networks in the cluster",
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVarP(&r.namespace, "namespace", "n", network.DefaultNamespace, "namespace of the networks")
+	cmd.Flags().BoolVarP(&r.allNamespaces, "all-namespaces", "A", false, "list the networks in all namespaces")
+	cmd.Flags().StringVarP(&r.output, "output", "o", "", "output format, one of text, json or yaml (defaults to the configured output)")
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.Values("text", "json", "yaml"))
+
+	return r
+}
+
+type Runner struct {
+	Command       *cobra.Command
+	namespace     string
+	allNamespaces bool
+	output        string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.allNamespaces {
+		r.namespace = ""
+	}
+	if r.output == "" {
+		r.output = config.Output()
+	}
+	k, _ := config.GetKey(config.KeyOutput)
+	return k.Validate(r.output)
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	nets, err := network.List(ctx, r.namespace)
+	if err != nil {
+		return err
+	}
+
+	switch r.output {
+	case "json":
+		enc := json.NewEncoder(c.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(nets)
+	case "yaml":
+		b, err := network.Marshal(nets)
+		if err != nil {
+			return err
+		}
+		_, err = c.OutOrStdout().Write(b)
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tTOPOLOGY\tTYPE\tREADY")
+	for _, n := range nets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", n.Metadata.Namespace, n.Metadata.Name, n.Spec.Topology, n.Type(), n.Ready())
+	}
+	return w.Flush()
+}
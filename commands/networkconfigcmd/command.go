@@ -18,13 +18,18 @@ package networkconfigcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/commands/networkconfigcmd/initcmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "apply the default ip index and network config, use init to generate your own"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,16 +38,16 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "networkconfig [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "networkconfig [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
 
 	r.Command = cmd
+	cmd.AddCommand(initcmd.NewCommand(ctx, version))
 
 	return r
 }
@@ -60,17 +65,36 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue the default network configuration (config parameters for the underlay)")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue the default network configuration (config parameters for the underlay)",
+		"Applies the ip index and the network config of the default network, the parameters kuid allocates the underlay addresses, AS numbers and ids from.",
+	)
+	x.SetName("networkconfig")
+	x.Require(
+		run.RequireExercise("inventory"),
+		run.RequireResource("ipindices.ipam.be.kuid.dev", "install"),
+		run.RequireResource("networkconfigs.network.app.kuid.dev", "install"),
+	)
 
 	x.Step(
 		run.S("apply the ip index (network prefixes the network is setup with)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/network/default-ipindex.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("network/default-ipindex.yaml")),
 	)
 
 	x.Step(
 		run.S("apply the network config (network parameters for your network, BGP, VXLAN, Prefixes)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/network/default-networkconfig.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("network/default-networkconfig.yaml")),
+		run.Expect("`kubectl get networkconfigs.network.app.kuid.dev` shows the network config Ready"),
+	)
+
+	x.Check(
+		run.S("the network config is Ready"),
+		run.S(checks.Ready("networkconfigs.network.app.kuid.dev")),
 	)
 
-	return x.Run(ctx)
+	return x
 }
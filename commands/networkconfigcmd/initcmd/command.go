@@ -0,0 +1,162 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "init [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "generate the ip index and network config for your own prefixes, AS and VNI ranges",
+		Long: `Generate the ip index and network config for the underlay.
+
+When running in a terminal the parameters are asked for, using the flags as
+defaults. The prefixes are checked for overlaps and the pools and ranges are
+checked to be large enough for the number of nodes in the topology.`,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVar(&r.cfg.Topology, "topology", "", "topology the network config applies to (defaults to the configured topology)")
+	cmd.Flags().StringVarP(&r.cfg.Namespace, "namespace", "n", network.DefaultNamespace, "namespace of the generated resources")
+	cmd.Flags().StringSliceVar(&r.cfg.UnderlayPrefixes, "underlay-prefix", []string{"10.0.0.0/8", "1000::/16"}, "prefixes of the ip index")
+	cmd.Flags().StringSliceVar(&r.cfg.LoopbackPools, "loopback-pool", []string{"10.0.0.0/16", "1000::/32"}, "pools the loopback addresses are allocated from")
+	cmd.Flags().StringSliceVar(&r.cfg.LinkPools, "link-pool", []string{"10.1.0.0/16", "1000:1::/32"}, "pools the point-to-point link addresses are allocated from")
+	cmd.Flags().StringVar(&r.cfg.ASRange, "as-range", "65000-65100", "range the ebgp AS numbers are allocated from")
+	cmd.Flags().StringVar(&r.cfg.VNIRange, "vni-range", "10000-20000", "range the vxlan VNIs are allocated from")
+	cmd.Flags().IntVar(&r.cfg.Nodes, "nodes", 0, "number of nodes in the topology (retrieved from the cluster when not set)")
+	cmd.Flags().BoolVar(&r.noPrompt, "no-prompt", false, "do not ask for the parameters, use the flags")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false, "print the resources instead of applying them")
+	_ = cmd.RegisterFlagCompletionFunc("topology", completion.Topologies)
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+
+	return r
+}
+
+type Runner struct {
+	Command  *cobra.Command
+	cfg      network.UnderlayConfig
+	noPrompt bool
+	dryRun   bool
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.cfg.Topology == "" {
+		r.cfg.Topology = config.Topology()
+	}
+	if !prompt.IsTerminal(os.Stdin) {
+		r.noPrompt = true
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	if r.cfg.Nodes == 0 {
+		topo, err := network.GetTopology(ctx, r.cfg.Topology)
+		if err != nil {
+			if r.noPrompt {
+				return fmt.Errorf("%w, use --nodes to size the pools without a cluster", err)
+			}
+		} else {
+			r.cfg.Nodes = len(topo.Nodes)
+		}
+	}
+
+	if !r.noPrompt {
+		if err := r.ask(prompt.New(os.Stdin, c.OutOrStdout())); err != nil {
+			return err
+		}
+	}
+
+	idx, nc, err := network.NewUnderlay(&r.cfg)
+	if err != nil {
+		return err
+	}
+	b, err := network.Marshal(idx, nc)
+	if err != nil {
+		return err
+	}
+
+	if r.dryRun {
+		_, err := c.OutOrStdout().Write(b)
+		return err
+	}
+
+	manifest, err := kubectl.NewManifest(b)
+	if err != nil {
+		return err
+	}
+
+	x := run.NewRun("Configue the network configuration (config parameters for the underlay)")
+	x.OnSetup(manifest.Write)
+	x.OnCleanup(manifest.Remove)
+
+	x.Step(
+		run.S("apply the generated ip index and network config"),
+		run.S("kubectl apply -f "+manifest.Path),
+	)
+
+	return x.Run(ctx)
+}
+
+// ask collects the parameters, the flag values are used as defaults.
+func (r *Runner) ask(p *prompt.Prompter) error {
+	var err error
+	if r.cfg.Nodes == 0 {
+		if r.cfg.Nodes, err = p.Int("number of nodes in the topology", 3); err != nil {
+			return err
+		}
+	}
+	if r.cfg.UnderlayPrefixes, err = p.List("underlay prefixes (ip index)", r.cfg.UnderlayPrefixes); err != nil {
+		return err
+	}
+	if r.cfg.LoopbackPools, err = p.List("loopback pools", r.cfg.LoopbackPools); err != nil {
+		return err
+	}
+	if r.cfg.LinkPools, err = p.List("link pools", r.cfg.LinkPools); err != nil {
+		return err
+	}
+	if r.cfg.ASRange, err = p.String("ebgp AS range", r.cfg.ASRange); err != nil {
+		return err
+	}
+	if r.cfg.VNIRange, err = p.String("vxlan VNI range", r.cfg.VNIRange); err != nil {
+		return err
+	}
+	return nil
+}
@@ -18,13 +18,18 @@ package networkdefaultcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "configure the default underlay network"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +38,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "networkdefault [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "networkdefault [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,12 +64,42 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue the default underlay network")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue the default underlay network",
+		"Creates the default network of the topology: the underlay with the interface addresses and the BGP sessions between the nodes.",
+	)
+	x.SetName("networkdefault")
+	x.Require(
+		run.RequireExercise("sdc"),
+		run.RequireExercise("networkconfig"),
+		run.RequireResource(network.Resource, "install"),
+		run.RequireObject("networkconfigs.network.app.kuid.dev", "default", config.Topology()+".default", "networkconfig"),
+	)
 
 	x.Step(
 		run.S("apply the default network config"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/network/default-network.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("network/default-network.yaml")),
+		run.Expect("`kubenet network describe default` shows the network Ready"),
+	)
+
+	x.Check(
+		run.S("the default network is Ready"),
+		run.S(checks.NetworkReady(config.Topology(), "default")),
+	)
+
+	x.Check(
+		run.S("the sdc config intents are applied on each target"),
+		run.S(checks.Ready("configs.config.sdcio.dev")),
+	)
+
+	x.Check(
+		run.S("the BGP sessions are established on the SR Linux nodes"),
+		run.S(checks.BGPEstablished(config.CurrentProfile().LabName())),
 	)
 
-	return x.Run(ctx)
+	return x
 }
@@ -18,13 +18,18 @@ package networkirbcmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "configure an IRB EVPN overlay network"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +38,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "networkirb [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "networkirb [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,12 +64,46 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue a IRB overlay EVPN network")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue a IRB overlay EVPN network",
+		"Creates network vpc3, an EVPN overlay with a bridge domain and a routing table joined by an IRB interface.",
+	)
+	x.SetName("networkirb")
+	x.Require(
+		run.RequireExercise("networkdefault"),
+		run.RequireObject(network.Resource, "default", config.Topology()+".default", "networkdefault"),
+	)
 
 	x.Step(
 		run.S("apply the default network config"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/network/vpc3-irb-network.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("network/vpc3-irb-network.yaml")),
+		run.Expect("`kubenet network describe vpc3` shows the network Ready"),
+	)
+
+	x.Check(
+		run.S("network vpc3 is Ready"),
+		run.S(checks.NetworkReady(config.Topology(), "vpc3")),
+	)
+
+	x.Check(
+		run.S("the sdc config intents are applied on each target"),
+		run.S(checks.Ready("configs.config.sdcio.dev")),
+	)
+
+	x.Check(
+		run.S("the BGP EVPN sessions are established on the SR Linux nodes"),
+		run.S(checks.BGPEstablished(config.CurrentProfile().LabName())),
+	)
+
+	// the lab material configures client2 with 10.2.0.2 in vpc3
+	x.Check(
+		run.S("client1 reaches client2 through the IRB interface"),
+		run.S(checks.Ping(config.CurrentProfile().LabName(), "client1", "10.2.0.2")),
 	)
 
-	return x.Run(ctx)
+	return x
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profilecmd
+
+import (
+	"context"
+
+	"github.com/kubenet-dev/kubenetctl/commands/profilecmd/deletecmd"
+	"github.com/kubenet-dev/kubenetctl/commands/profilecmd/listcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/profilecmd/usecmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "manage the lab profiles, to run several labs side by side",
+		Long: `Manage the lab profiles.
+
+A profile namespaces the kind cluster, the containerlab lab, the docker network,
+the kubeconfig and the run state of a lab, e.g.
+
+  kubenet --profile lab2 setup`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(listcmd.NewCommand(ctx, version))
+	cmd.AddCommand(usecmd.NewCommand(ctx, version))
+	cmd.AddCommand(deletecmd.NewCommand(ctx, version))
+	return cmd
+}
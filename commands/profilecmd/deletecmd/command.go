@@ -0,0 +1,101 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletecmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "delete PROFILE [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "delete the kubeconfig and run state of a profile",
+		Long:              "Delete the kubeconfig and run state of a profile. The lab of the profile must be\ndestroyed first with `kubenet --profile PROFILE destroy`.",
+		ValidArgsFunction: completion.Profiles,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	cmd.Flags().BoolVar(&r.Force, "force", false, "delete the profile even when its kind cluster still exists")
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+	Force   bool
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, args []string) error {
+	if err := config.ValidateProfileName(args[0]); err != nil {
+		return err
+	}
+	if args[0] == config.DefaultProfile {
+		return fmt.Errorf("the %s profile cannot be deleted", config.DefaultProfile)
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	p := config.Profile{Name: args[0]}
+	if !r.Force && clusterExists(c.Context(), p.ClusterName()) {
+		return exitcode.Preconditionf("kind cluster %s of profile %s still exists, run `kubenet --profile %s destroy` first or use --force", p.ClusterName(), p.Name, p.Name)
+	}
+	if err := p.Delete(); err != nil {
+		return fmt.Errorf("cannot delete profile %s: %w", p.Name, err)
+	}
+	fmt.Fprintf(c.OutOrStdout(), "deleted profile %s\n", p.Name)
+	if config.CurrentProfile().Name == p.Name {
+		fmt.Fprintf(c.OutOrStdout(), "note: %s is still the current profile, run `kubenet profile use %s`\n", p.Name, config.DefaultProfile)
+	}
+	return nil
+}
+
+// clusterExists returns true when kind lists the cluster. When kind is not
+// available the cluster is assumed to be gone.
+func clusterExists(ctx context.Context, name string) bool {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out, err := exec.CommandContext(ctx, "kind", "get", "clusters").Output()
+	if err != nil {
+		return false
+	}
+	for _, cluster := range strings.Fields(string(out)) {
+		if cluster == name {
+			return true
+		}
+	}
+	return false
+}
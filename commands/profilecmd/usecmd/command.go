@@ -0,0 +1,73 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "use PROFILE [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "make a profile the default for the next commands",
+		Example:           "  kubenet profile use lab2\n  kubenet profile use default",
+		ValidArgsFunction: completion.Profiles,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, args []string) error {
+	return config.ValidateProfileName(args[0])
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	f, err := config.ReadFile(config.FileUsed())
+	if err != nil {
+		return err
+	}
+	f.Settings[config.KeyProfile] = args[0]
+	if err := f.Write(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutOrStdout(), "using profile %s\n", args[0])
+	if source := config.SourceOf(config.KeyProfile, c.Flags()); source == config.SourceEnv {
+		k, _ := config.GetKey(config.KeyProfile)
+		fmt.Fprintf(c.OutOrStdout(), "note: %s overrides the config file\n", k.Env())
+	}
+	return nil
+}
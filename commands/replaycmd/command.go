@@ -0,0 +1,77 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replaycmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/cast"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:               "replay FILE [flags]",
+		Args:              cobra.ExactArgs(1),
+		Short:             "play back a session recorded with --record without running anything",
+		ValidArgsFunction: completion.Recordings,
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().Float64Var(&r.opts.Speed, "speed", 1, "playback speed multiplier")
+	cmd.Flags().DurationVar(&r.opts.IdleLimit, "idle-limit", 2*time.Second, "maximum pause between output, 0 keeps the recorded pauses")
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+	opts    cast.PlayOptions
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.opts.Speed <= 0 {
+		return fmt.Errorf("--speed must be positive")
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, events, err := cast.Read(f)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", args[0], err)
+	}
+	return cast.Play(c.Context(), c.OutOrStdout(), events, r.opts)
+}
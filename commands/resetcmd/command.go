@@ -0,0 +1,100 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resetcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "reset EXERCISE [flags]",
+		Args:  cobra.ExactArgs(1),
+		Short: "undo an exercise, e.g. to do it again",
+		Long: `Undo an exercise, e.g. to do it again.
+
+The cleanup steps of the exercise run first, then the manifests the
+exercise applied are deleted in reverse order, waiting for the finalizers
+of their objects. The exercise and the exercises that require it are no
+longer completed afterwards. Resetting setup destroys the environment.`,
+		Example:           "  kubenet reset networkbridged\n  kubenet reset networkbridged --diff",
+		ValidArgsFunction: completion.Values(runbooks.Names()...),
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	rb, err := runbooks.Get(args[0])
+	if err != nil {
+		return err
+	}
+	x := rb.New().Undo()
+	if !x.HasSteps() {
+		return fmt.Errorf("exercise %s has nothing to undo", rb.Name)
+	}
+	if err := x.Run(c.Context()); err != nil {
+		return err
+	}
+	if diff, _ := c.Flags().GetBool("diff"); diff {
+		return nil
+	}
+	return resetDependents(rb.Name)
+}
+
+// resetDependents clears the completion of the exercises that require the
+// exercise, directly or through another exercise.
+func resetDependents(name string) error {
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	reset := map[string]bool{name: true}
+	// the runbooks are in order, an exercise follows the ones it requires
+	for _, rb := range runbooks.All {
+		for _, req := range rb.Requires() {
+			if reset[req] {
+				reset[rb.Name] = true
+			}
+		}
+	}
+	for n := range reset {
+		if e, ok := prog.Exercises[n]; ok {
+			e.Completed = nil
+		}
+	}
+	return prog.Save()
+}
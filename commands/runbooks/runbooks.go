@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runbooks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/commands/destroycmd"
+	"github.com/kubenet-dev/kubenetctl/commands/installcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/invcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkbridgedcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkconfigcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkdefaultcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkirbcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/networkroutedcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/sdccmd"
+	"github.com/kubenet-dev/kubenetctl/commands/setupcmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+)
+
+// Runbook is a runbook of kubenetctl, the name is the command that runs it.
+type Runbook struct {
+	Name    string
+	Summary string
+	// Estimate is the estimated time to do the exercise by hand.
+	Estimate time.Duration
+	New      func() *run.Run
+}
+
+// All lists the runbooks in the order of the exercises.
+var All = []Runbook{
+	{Name: "setup", Summary: setupcmd.Short, Estimate: 10 * time.Minute, New: setupcmd.Runbook},
+	{Name: "install", Summary: installcmd.Short, Estimate: 5 * time.Minute, New: installcmd.Runbook},
+	{Name: "sdc", Summary: sdccmd.Short, Estimate: 10 * time.Minute, New: sdccmd.Runbook},
+	{Name: "inventory", Summary: invcmd.Short, Estimate: 5 * time.Minute, New: invcmd.Runbook},
+	{Name: "networkconfig", Summary: networkconfigcmd.Short, Estimate: 10 * time.Minute, New: networkconfigcmd.Runbook},
+	{Name: "networkdefault", Summary: networkdefaultcmd.Short, Estimate: 15 * time.Minute, New: networkdefaultcmd.Runbook},
+	{Name: "networkbridged", Summary: networkbridgedcmd.Short, Estimate: 15 * time.Minute, New: networkbridgedcmd.Runbook},
+	{Name: "networkrouted", Summary: networkroutedcmd.Short, Estimate: 15 * time.Minute, New: networkroutedcmd.Runbook},
+	{Name: "networkirb", Summary: networkirbcmd.Short, Estimate: 15 * time.Minute, New: networkirbcmd.Runbook},
+	{Name: "destroy", Summary: destroycmd.Short, Estimate: 2 * time.Minute, New: destroycmd.Runbook},
+}
+
+// Get returns the runbook with the name.
+func Get(name string) (Runbook, error) {
+	for _, rb := range All {
+		if rb.Name == name {
+			return rb, nil
+		}
+	}
+	return Runbook{}, fmt.Errorf("unknown runbook %q, expected one of %v", name, Names())
+}
+
+// Names returns the names of the runbooks.
+func Names() []string {
+	names := make([]string, 0, len(All))
+	for _, rb := range All {
+		names = append(names, rb.Name)
+	}
+	return names
+}
+
+// Requires returns the exercises that must be done first.
+func (r Runbook) Requires() []string {
+	requires := []string{}
+	for _, req := range r.New().Requirements() {
+		if req.Text == "" {
+			requires = append(requires, req.Exercise)
+		}
+	}
+	return requires
+}
+
+// Creates describes what the steps of the runbook create: the plan of a
+// host step or the manifest a step applies.
+func Creates(x *run.Run) []string {
+	base := config.KubenetURL("")
+	creates := []string{}
+	for _, s := range x.Steps() {
+		cmd := strings.Join(s.Command, " ")
+		switch {
+		case s.Plan != "":
+			creates = append(creates, strings.TrimPrefix(s.Plan, "would "))
+		case strings.HasPrefix(cmd, "kubectl apply -f "):
+			creates = append(creates, "manifest "+strings.TrimPrefix(strings.TrimPrefix(cmd, "kubectl apply -f "), base))
+		}
+	}
+	return creates
+}
+
+// EstimatedTime returns the estimate in minutes, e.g. 15m.
+func (r Runbook) EstimatedTime() string {
+	return fmt.Sprintf("%.0fm", r.Estimate.Minutes())
+}
@@ -18,13 +18,19 @@ package sdccmd
 
 import (
 	"context"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/commands/sdccmd/secretcmd"
+	"github.com/kubenet-dev/kubenetctl/commands/sdccmd/secretcmd/setcmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "configure sdc to discover and connect to the SR Linux nodes"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,16 +39,16 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "sdc [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "sdc [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
 
 	r.Command = cmd
+	cmd.AddCommand(secretcmd.NewCommand(ctx, version))
 
 	return r
 }
@@ -60,32 +66,57 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Configue sdc")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	x := run.NewRun("Configue sdc",
+		"Applies the SR Linux yang schema, the gnmi connection and sync profiles, the node credentials and a discovery rule, so sdc discovers the containerlab nodes as targets.",
+	)
+	x.SetName("sdc")
+	x.Require(
+		run.RequireExercise("install"),
+		run.RequireResource("schemas.inv.sdcio.dev", "install"),
+	)
 
 	x.Step(
 		run.S("apply the schema for srlinux 24.3.2"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/sdc/schemas/srl24-3-2.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("sdc/schemas/srl24-3-2.yaml")),
+		run.Group("profiles"),
 	)
 
 	x.Step(
 		run.S("apply the gnmi profile to connect to the target (clab node)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/sdc/profiles/conn-gnmi-skipverify.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("sdc/profiles/conn-gnmi-skipverify.yaml")),
+		run.Group("profiles"),
 	)
 
 	x.Step(
 		run.S("apply the gnmi sync profile to sync config from the target (clab node)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/sdc/profiles/sync-gnmi-get.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("sdc/profiles/sync-gnmi-get.yaml")),
+		run.Group("profiles"),
 	)
 
+	// the credentials set with `kubenet sdc secret set` are kept
 	x.Step(
-		run.S("apply the srl secret with credentials to authenticate to the target (clab node)"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/sdc/profiles/secret.yaml"),
+		run.S("apply the srl secret with credentials to authenticate to the target (clab node), unless it exists"),
+		run.S("kubectl get secret "+setcmd.DefaultName+" -n default >/dev/null 2>&1 ||",
+			"kubectl apply -f "+config.KubenetURL("sdc/profiles/secret.yaml")),
+		run.Plan("would apply the default srl secret %s unless it exists", setcmd.DefaultName),
+		run.Group("profiles"),
 	)
 
 	x.Step(
 		run.S("apply the discovery rule to discover the srl devices deployed by containerlab"),
-		run.S("kubectl apply -f https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/sdc/drrules/dr-dynamic.yaml"),
+		run.S("kubectl apply -f "+config.KubenetURL("sdc/drrules/dr-dynamic.yaml")),
+		run.Expect("`kubectl get targets` lists the discovered SR Linux nodes"),
+	)
+
+	x.Check(
+		run.S("sdc discovered the targets and they are Ready"),
+		run.S(checks.Ready("targets.inv.sdcio.dev")),
 	)
 
-	return x.Run(ctx)
+	return x
 }
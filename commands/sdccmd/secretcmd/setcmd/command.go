@@ -0,0 +1,125 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setcmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/kubenet-dev/kubenetctl/pkg/secret"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// DefaultName is the secret the connection profiles of the lab material
+// refer to.
+const DefaultName = "srl.nokia.sdcio.dev"
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "set [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "set the credentials sdc connects to the targets with, e.g. of real hardware",
+		Long: `Set the credentials sdc connects to the targets with.
+
+The password is read from the terminal without echoing it, from an
+environment variable, from a file or from the output of a password manager,
+and it is redacted from the output and the recording. It is passed to
+kubectl on stdin, never on the command line.
+
+The credentials can be set before or after kubenet sdc deploys sdc. sdc
+only applies the default SR Linux credentials of the lab material when the
+secret does not exist, the credentials are kept when sdc is redeployed.`,
+		Example: `  kubenet sdc secret set --username admin
+  kubenet sdc secret set --password-from env:SRL_PASSWORD
+  kubenet sdc secret set --password-from file:~/.srl-password
+  kubenet sdc secret set --password-from "exec:pass show lab/srl"
+  kubenet sdc secret set --password-from "exec:op read op://lab/srl/password"`,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVar(&r.name, "name", DefaultName, "name of the secret")
+	cmd.Flags().StringVarP(&r.namespace, "namespace", "n", "default", "namespace of the secret")
+	cmd.Flags().StringVar(&r.username, "username", "admin", "username of the targets")
+	cmd.Flags().StringVar(&r.from, "password-from", "", "source of the password, one of "+secret.Sources+" (defaults to prompt)")
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces)
+	_ = cmd.RegisterFlagCompletionFunc("password-from", completion.Values(secret.SourcePrompt, secret.SourceEnv, secret.SourceFile, secret.SourceExec))
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	name      string
+	namespace string
+	username  string
+	from      string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.from == "" {
+		r.from = secret.SourcePrompt
+	}
+	if r.from == secret.SourcePrompt && !prompt.IsTerminal(os.Stdin) {
+		return exitcode.Preconditionf("no terminal to ask the password, use --password-from env:NAME, file:PATH or exec:COMMAND")
+	}
+	return nil
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	password, err := secret.Read(ctx, r.from, viper.GetString(config.KeyShell), "password of "+r.username)
+	if err != nil {
+		return err
+	}
+
+	return r.Runbook(password).Run(ctx)
+}
+
+// Runbook returns the runbook that sets the credentials. The password is
+// written to the stdin of kubectl by the printf builtin of the shell, so it
+// is in no command line.
+func (r *Runner) Runbook(password string) *run.Run {
+	x := run.NewRun("Set the credentials of the targets")
+
+	x.Step(
+		run.S("create the secret with the credentials sdc connects to the targets with"),
+		run.S(`printf %s "$SDC_PASSWORD" |`,
+			"kubectl create secret generic "+r.name+" -n "+r.namespace+" --type=kubernetes.io/basic-auth",
+			`--from-literal=username="$SDC_USERNAME" --from-file=password=/dev/stdin`,
+			"--dry-run=client -o yaml | kubectl apply -f -"),
+		run.Env("SDC_USERNAME", r.username),
+		run.SecretEnv("SDC_PASSWORD", password),
+		run.Plan("would set the credentials of secret %s/%s", r.namespace, r.name),
+	)
+
+	return x
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setcmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const password = "s3cr3t-Pa55"
+
+func TestRunbookPasswordNotOnCommandLine(t *testing.T) {
+	r := &Runner{name: DefaultName, namespace: "default", username: "admin"}
+	x := r.Runbook(password)
+	for _, s := range x.Steps() {
+		if line := strings.Join(s.Command, " "); strings.Contains(line, password) {
+			t.Fatalf("command %q contains the password", line)
+		}
+	}
+
+	// a fake kubectl logs its arguments and passes its stdin through
+	dir := t.TempDir()
+	kubectl := "#!/bin/sh\necho \"$@\" >> " + filepath.Join(dir, "argv") + "\ntee -a " + filepath.Join(dir, "stdin") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(kubectl), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// the step runs like the runbook runs it, with the password in the
+	// environment of the shell
+	cmd := exec.Command("sh", "-c", strings.Join(x.Steps()[0].Command, " "))
+	cmd.Env = append(os.Environ(), "SDC_USERNAME=admin", "SDC_PASSWORD="+password)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("step failed: %v: %s", err, out)
+	}
+	argv, err := os.ReadFile(filepath.Join(dir, "argv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(argv), password) {
+		t.Errorf("kubectl arguments contain the password:\n%s", argv)
+	}
+	if !strings.Contains(string(argv), "--from-file=password=/dev/stdin") {
+		t.Errorf("kubectl arguments do not read the password from stdin:\n%s", argv)
+	}
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(stdin), password) {
+		t.Errorf("kubectl stdin = %q, want the password", stdin)
+	}
+}
@@ -18,13 +18,20 @@ package setupcmd
 
 import (
 	"context"
+	"errors"
+	"strings"
 
-	//docs "github.com/pkgserver-dev/pkgserver/internal/docs/generated/initdocs"
-
+	"github.com/kubenet-dev/kubenetctl/commands/destroycmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/checks"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
 	"github.com/kubenet-dev/kubenetctl/pkg/run"
 	"github.com/spf13/cobra"
 )
 
+// Short is the summary of the exercise.
+const Short = "create the kind cluster and deploy the containerlab topology"
+
 func NewCommand(ctx context.Context, version string) *cobra.Command {
 	return NewRunner(ctx, version).Command
 }
@@ -33,11 +40,10 @@ func NewCommand(ctx context.Context, version string) *cobra.Command {
 func NewRunner(ctx context.Context, version string) *Runner {
 	r := &Runner{}
 	cmd := &cobra.Command{
-		Use:  "setup [flags]",
-		Args: cobra.ExactArgs(0),
-		//Short:   docs.InitShort,
-		//Long:    docs.InitShort + "\n" + docs.InitLong,
-		//Example: docs.InitExamples,
+		Use:     "setup [flags]",
+		Args:    cobra.ExactArgs(0),
+		Short:   Short,
+		Long:    strings.Join(Runbook().Description(), "\n\n"),
 		PreRunE: r.preRunE,
 		RunE:    r.runE,
 	}
@@ -60,22 +66,75 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	//log := log.FromContext(ctx)
 	//log.Info("create packagerevision", "src", args[0], "dst", args[1])
 
-	x := run.NewRun("Setup kubenet Environment")
+	return Runbook().Run(ctx)
+}
+
+// Runbook returns the runbook of the exercise.
+func Runbook() *run.Run {
+	p := config.CurrentProfile()
+	kind := "kind create cluster --name " + p.ClusterName()
+	clab := "sudo containerlab deploy -t " + config.KubenetURL("lab/3node.yaml") + " --reconfigure"
+	if !p.IsDefault() {
+		// the kind cluster and containerlab lab of a profile share their own
+		// docker network
+		kind = "KIND_EXPERIMENTAL_DOCKER_NETWORK=" + p.DockerNetwork() + " " + kind
+		clab += " --name " + p.LabName() + " --network " + p.DockerNetwork()
+	}
+
+	x := run.NewRun("Setup kubenet Environment",
+		"Creates a kind cluster for the kubenet components and deploys the 3 node SR Linux containerlab topology next to it, on a docker network the cluster can reach.",
+	)
+	x.SetName("setup")
 
 	x.Step(
 		run.S("create k8s kind cluster"),
-		run.S("kind create cluster --name kubenet"),
+		run.S(kind),
+		run.Plan("would create kind cluster %s", p.ClusterName()),
+		run.Expect("`kubectl get nodes` shows the %s-control-plane node Ready", p.ClusterName()),
 	)
 
+	rule := "DOCKER-USER -o br-$(docker network inspect -f '{{ printf \"%.12s\" .ID }}' " + p.DockerNetwork() + ") -j ACCEPT"
 	x.Step(
 		run.S("Allow the kind cluster to communicate with the containerlab topology (clab will be created in a later step)"),
-		run.S("sudo iptables -I DOCKER-USER -o br-$(docker network inspect -f '{{ printf \"%.12s\" .ID }}' kind) -j ACCEPT"),
+		run.S("sudo iptables -I "+rule),
+		run.Plan("would insert iptables rule in the DOCKER-USER chain accepting traffic to the %s network", p.DockerNetwork()),
+		run.HostOnly(),
 	)
+	// a later step that fails leaves no lab behind the inserted rule
+	inserted := len(x.Steps())
+	x.OnStepFailure(func(ctx context.Context, err error) error {
+		var serr *exitcode.StepError
+		if !errors.As(err, &serr) || serr.Step <= inserted {
+			return nil
+		}
+		return x.Exec(ctx, "sudo iptables -D "+rule, run.HostOnly())
+	})
 
 	x.Step(
 		run.S("Deploy Containerlab topology"),
-		run.S("sudo containerlab deploy -t https://raw.githubusercontent.com/kubenet-dev/kubenet/v0.0.1/lab/3node.yaml --reconfigure"),
+		run.S(clab),
+		run.Plan("would deploy containerlab topology 3node (existing lab is reconfigured)"),
+		run.Expect("`sudo containerlab inspect --all` lists the SR Linux nodes of the 3node lab running"),
+	)
+
+	// undoing the setup destroys the environment
+	for _, s := range destroycmd.Runbook().Steps() {
+		opts := []run.StepOption{run.Plan("%s", s.Plan)}
+		if s.HostOnly {
+			opts = append(opts, run.HostOnly())
+		}
+		x.Cleanup(s.Text, s.Command, opts...)
+	}
+
+	x.Check(
+		run.S("the nodes of the kind cluster are Ready"),
+		run.S(checks.NodesReady()),
+	)
+
+	x.Check(
+		run.S("the containerlab nodes are running"),
+		run.S(checks.LabRunning(p.LabName())),
 	)
 
-	return x.Run(ctx)
+	return x
 }
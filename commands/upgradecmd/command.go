@@ -0,0 +1,164 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradecmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/release"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{
+		current: version,
+	}
+	cmd := &cobra.Command{
+		Use:   "upgrade [flags]",
+		Args:  cobra.NoArgs,
+		Short: "upgrade kubenet to latest available version",
+		Long: `Upgrade kubenet to the latest or the selected release.
+
+The release archive is verified against the sha256 checksums of the release and,
+when a minisign public key is provided, the checksums file is verified against
+its signature. The binary is replaced atomically and restored when the new
+binary fails to run.`,
+		Example: "  kubenet version upgrade --check\n  kubenet version upgrade --version v0.0.2",
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+
+	cmd.Flags().StringVar(&r.Version, "version", "", "release to install, defaults to the latest release")
+	cmd.Flags().BoolVar(&r.Check, "check", false, "only report whether an update is available")
+	cmd.Flags().StringVar(&r.PublicKey, "public-key", "", "minisign public key or key file, requires a signed checksums file")
+	cmd.Flags().BoolVar(&r.Force, "force", false, "reinstall when the release is already installed")
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	Version   string
+	Check     bool
+	PublicKey string
+	Force     bool
+	current   string
+}
+
+func (r *Runner) runE(c *cobra.Command, _ []string) error {
+	ctx := c.Context()
+	out := c.OutOrStdout()
+
+	src := release.NewGitHub(config.ReleaseURL())
+	// the token avoids the rate limit of the GitHub API
+	src.Token = os.Getenv("GITHUB_TOKEN")
+
+	var rel *release.Release
+	var err error
+	if r.Version != "" {
+		rel, err = src.Get(ctx, r.Version)
+	} else {
+		rel, err = src.Latest(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.Check {
+		if release.Compare(rel.Tag, r.current) > 0 {
+			fmt.Fprintf(out, "kubenet %s is available, you have %s\n", rel.Tag, release.Tag(r.current))
+			fmt.Fprintf(out, "run `kubenet version upgrade` to upgrade\n")
+			return nil
+		}
+		fmt.Fprintf(out, "kubenet %s is up to date\n", release.Tag(r.current))
+		return nil
+	}
+	if release.Tag(r.current) == rel.Tag && !r.Force {
+		fmt.Fprintf(out, "kubenet is already at %s\n", rel.Tag)
+		return nil
+	}
+
+	binary, err := r.fetch(ctx, src, rel)
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	target, err := release.Executable()
+	if err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	if err := release.Replace(ctx, target, binary); err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	fmt.Fprintf(out, "kubenet upgraded from %s to %s in %s\n", release.Tag(r.current), rel.Tag, target)
+	return nil
+}
+
+// fetch downloads the archive of the release, verifies it and returns the
+// binary it contains.
+func (r *Runner) fetch(ctx context.Context, src *release.GitHub, rel *release.Release) ([]byte, error) {
+	name, url, err := rel.Archive()
+	if err != nil {
+		return nil, err
+	}
+	sumName, sumURL, err := rel.Checksums()
+	if err != nil {
+		return nil, err
+	}
+	var sums bytes.Buffer
+	if err := release.Download(ctx, src.Client, sumURL, &sums); err != nil {
+		return nil, err
+	}
+	if r.PublicKey != "" {
+		key, err := release.ParsePublicKey(r.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		sigURL, ok := rel.Assets[sumName+".minisig"]
+		if !ok {
+			return nil, fmt.Errorf("release %s has no signature for %s", rel.Tag, sumName)
+		}
+		var sig bytes.Buffer
+		if err := release.Download(ctx, src.Client, sigURL, &sig); err != nil {
+			return nil, err
+		}
+		if err := key.VerifyMinisign(sums.Bytes(), sig.Bytes()); err != nil {
+			return nil, fmt.Errorf("%s: %w", sumName, err)
+		}
+		fmt.Fprintf(r.Command.OutOrStdout(), "signature of %s verified\n", sumName)
+	}
+
+	fmt.Fprintf(r.Command.OutOrStdout(), "downloading %s\n", url)
+	var archive bytes.Buffer
+	if err := release.Download(ctx, src.Client, url, &archive); err != nil {
+		return nil, err
+	}
+	if err := release.VerifySHA256(sums.Bytes(), name, archive.Bytes()); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(r.Command.OutOrStdout(), "checksum of %s verified\n", name)
+	return release.Extract(archive.Bytes(), release.Project)
+}
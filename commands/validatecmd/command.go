@@ -0,0 +1,99 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validatecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/validate"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText  = "text"
+	outputSARIF = "sarif"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{version: version}
+	cmd := &cobra.Command{
+		Use:   "validate FILE|DIR... [flags]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "validate kubenet manifests offline before applying them",
+		Long: `Validate kubenet manifests offline before applying them.
+
+The resources are validated against the bundled subsets of the CRD schemas of
+the kubenet components, prefixes and AS and VNI pools are checked to be valid.
+Networks are checked to refer to nodes and endpoints of a topology and network
+and network config prefixes to lie inside an ip index, when the topology or ip
+index is part of the validated files. Resources defined more than once are
+reported as duplicates.`,
+		Example: `  kubenet validate network/
+  kubenet validate topo.yaml vpc1.yaml --output sarif > kubenet.sarif`,
+		PreRunE: r.preRunE,
+		RunE:    r.runE,
+	}
+
+	r.Command = cmd
+	cmd.Flags().StringVarP(&r.output, "output", "o", outputText, "output format, one of text or sarif")
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.Values(outputText, outputSARIF))
+
+	return r
+}
+
+type Runner struct {
+	Command *cobra.Command
+	version string
+	output  string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	switch r.output {
+	case outputText, outputSARIF:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, expected %s or %s", r.output, outputText, outputSARIF)
+	}
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	findings, err := validate.Validate(args...)
+	if err != nil {
+		return err
+	}
+
+	switch r.output {
+	case outputSARIF:
+		err = validate.WriteSARIF(c.OutOrStdout(), r.version, findings)
+	default:
+		err = validate.WriteText(c.OutOrStdout(), findings)
+	}
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("validation failed with %d errors", len(findings))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifycmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/commands/runbooks"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+func NewCommand(ctx context.Context, version string) *cobra.Command {
+	return NewRunner(ctx, version).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, version string) *Runner {
+	r := &Runner{}
+	cmd := &cobra.Command{
+		Use:   "verify EXERCISE [flags]",
+		Args:  cobra.ExactArgs(1),
+		Short: "check that an exercise worked, e.g. that the network is Ready and BGP is established",
+		Long: `Check that an exercise worked.
+
+The checks of the exercise are retried until they all pass or the timeout
+expires. The same checks run at the end of the exercise itself.`,
+		Example:           "  kubenet verify networkbridged\n  kubenet verify networkbridged --timeout 1m -o json",
+		ValidArgsFunction: completion.Values(runbooks.Names()...),
+		PreRunE:           r.preRunE,
+		RunE:              r.runE,
+	}
+
+	r.Command = cmd
+
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0, "deadline of the checks (defaults to the configured timeout)")
+	cmd.Flags().DurationVar(&r.interval, "interval", run.DefaultVerifyInterval, "pause between the attempts of a check")
+	cmd.Flags().StringVarP(&r.output, "output", "o", "", "output format, one of text, json or yaml (defaults to the configured output)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.Values("text", "json", "yaml"))
+
+	return r
+}
+
+type Runner struct {
+	Command  *cobra.Command
+	timeout  time.Duration
+	interval time.Duration
+	output   string
+}
+
+func (r *Runner) preRunE(_ *cobra.Command, _ []string) error {
+	if r.timeout == 0 {
+		r.timeout = config.Timeout()
+	}
+	if r.output == "" {
+		r.output = config.Output()
+	}
+	k, _ := config.GetKey(config.KeyOutput)
+	return k.Validate(r.output)
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	rb, err := runbooks.Get(args[0])
+	if err != nil {
+		return err
+	}
+	x := rb.New()
+	if !x.HasChecks() {
+		return fmt.Errorf("exercise %s has no checks", rb.Name)
+	}
+
+	report, err := x.Verify(c.Context(), run.VerifyOptions{
+		Timeout:  r.timeout,
+		Interval: r.interval,
+		Shell:    viper.GetString(config.KeyShell),
+	})
+	if report != nil {
+		if werr := r.write(c, report); werr != nil {
+			return werr
+		}
+	}
+	if err == nil && report.Passed {
+		return markCompleted(rb.Name)
+	}
+	return err
+}
+
+// markCompleted records the exercise as completed in the progress.
+func markCompleted(name string) error {
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	prog.Get(name).Complete()
+	return prog.Save()
+}
+
+func (r *Runner) write(c *cobra.Command, report *run.Report) error {
+	switch r.output {
+	case "json":
+		enc := json.NewEncoder(c.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(c.OutOrStdout())
+		enc.SetIndent(2)
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return report.Write(c.OutOrStdout(), !color.Enable)
+}
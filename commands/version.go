@@ -18,15 +18,22 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
-	"os/exec"
-
+	"runtime"
+	"runtime/debug"
+	"text/tabwriter"
+
+	"github.com/kubenet-dev/kubenetctl/commands/upgradecmd"
+	"github.com/kubenet-dev/kubenetctl/pkg/completion"
+	"github.com/kubenet-dev/kubenetctl/pkg/components"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// set with -ldflags "-X github.com/kubenet-dev/kubenetctl/commands.version=..."
 var (
 	version = "0.0.0"
 	commit  = "none"
@@ -34,76 +41,151 @@ var (
 )
 
 const (
-	repoUrl     = "https://github.com/kubenet-dev/kubenetctl"
-	downloadURL = "https://github.com/kubenet-dev/kubenetctl/raw/main/install.sh"
+	repoUrl = "https://github.com/kubenet-dev/kubenetctl"
 )
 
-func GetVersionCommand(ctx context.Context) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "version",
-		Short: "show kubenet version",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("    version: %s\n", version)
-			fmt.Printf("     commit: %s\n", commit)
-			fmt.Printf("       date: %s\n", date)
-			fmt.Printf("     source: %s\n", repoUrl)
-			fmt.Printf(" rel. notes: https://learn.kubenet.dev/rn/%s\n", version)
+func init() {
+	readBuildInfo()
+}
 
-			return nil
-		},
+// readBuildInfo fills the version variables that are not set with ldflags
+// from the build info, e.g. for a `go install` build.
+func readBuildInfo() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if version == "0.0.0" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if commit == "none" {
+				commit = s.Value
+				if len(commit) > 7 {
+					commit = commit[:7]
+				}
+			}
+		case "vcs.time":
+			if date == "unknown" {
+				date = s.Value
+			}
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if modified && commit != "none" {
+		commit += "-dirty"
 	}
+}
 
-	cmd.AddCommand(GetVersionUpgradeCommand(ctx))
-	return cmd
+type versionInfo struct {
+	Version      string             `json:"version" yaml:"version"`
+	Commit       string             `json:"commit" yaml:"commit"`
+	Date         string             `json:"date" yaml:"date"`
+	GoVersion    string             `json:"goVersion" yaml:"goVersion"`
+	Platform     string             `json:"platform" yaml:"platform"`
+	Source       string             `json:"source" yaml:"source"`
+	ReleaseNotes string             `json:"releaseNotes" yaml:"releaseNotes"`
+	Tools        []components.Tool  `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Context      string             `json:"context,omitempty" yaml:"context,omitempty"`
+	Images       []components.Image `json:"images,omitempty" yaml:"images,omitempty"`
+	ImagesError  string             `json:"imagesError,omitempty" yaml:"imagesError,omitempty"`
 }
 
-func GetVersionUpgradeCommand(ctx context.Context) *cobra.Command {
+func GetVersionCommand(ctx context.Context) *cobra.Command {
+	var output string
+	var withComponents bool
 	cmd := &cobra.Command{
-		Use:   "upgrade",
-		Short: "upgrade kubenet to latest available version",
-		//PreRunE: sudoCheck,
+		Use:   "version",
+		Short: "show kubenet version",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				output = config.Output()
+			}
+			k, _ := config.GetKey(config.KeyOutput)
+			return k.Validate(output)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			f, err := os.CreateTemp("", "kubenet")
-			defer os.Remove(f.Name())
-			if err != nil {
-				return fmt.Errorf("failed to create temp file: %w", err)
+			info := versionInfo{
+				Version:      version,
+				Commit:       commit,
+				Date:         date,
+				GoVersion:    runtime.Version(),
+				Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+				Source:       repoUrl,
+				ReleaseNotes: "https://learn.kubenet.dev/rn/" + version,
 			}
-			_ = downloadFile(downloadURL, f)
-
-			c := exec.Command("bash", f.Name())
-			// pass the environment variables to the upgrade script
-			// so that GITHUB_TOKEN is available
-			c.Env = os.Environ()
-
-			c.Stdout = os.Stdout
-			c.Stderr = os.Stderr
-			err = c.Run()
-			if err != nil {
-				return fmt.Errorf("upgrade failed: %w", err)
+			if withComponents {
+				info.Tools = components.Tools(cmd.Context())
+				info.Context = config.CurrentProfile().KubeContext()
+				images, err := components.Images(cmd.Context())
+				if err != nil {
+					info.ImagesError = err.Error()
+				}
+				info.Images = images
 			}
 
-			return nil
+			switch output {
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			case "yaml":
+				enc := yaml.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent(2)
+				if err := enc.Encode(info); err != nil {
+					return err
+				}
+				return enc.Close()
+			}
+			return info.write(cmd.OutOrStdout(), withComponents)
 		},
 	}
 
-	cmd.AddCommand()
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format, one of text, json or yaml (defaults to the configured output)")
+	cmd.Flags().BoolVar(&withComponents, "components", false, "also show the versions of the tools and the images in the cluster, useful when filing a bug")
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.Values("text", "json", "yaml"))
+
+	cmd.AddCommand(upgradecmd.NewCommand(ctx, version))
 	return cmd
 }
 
-// downloadFile will download a file from a URL and write its content to a file.
-func downloadFile(url string, file *os.File) error {
-	// Get the data
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+func (r versionInfo) write(out io.Writer, withComponents bool) error {
+	fmt.Fprintf(out, "    version: %s\n", r.Version)
+	fmt.Fprintf(out, "     commit: %s\n", r.Commit)
+	fmt.Fprintf(out, "       date: %s\n", r.Date)
+	fmt.Fprintf(out, "         go: %s %s\n", r.GoVersion, r.Platform)
+	fmt.Fprintf(out, "     source: %s\n", r.Source)
+	fmt.Fprintf(out, " rel. notes: %s\n", r.ReleaseNotes)
+	if !withComponents {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	// Write the body to file
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
+	fmt.Fprintf(out, "\ntools:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	for _, t := range r.Tools {
+		v := t.Version
+		if t.Error != "" {
+			v = "(" + t.Error + ")"
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", t.Name, v)
+	}
+	if err := w.Flush(); err != nil {
 		return err
 	}
 
-	return nil
+	fmt.Fprintf(out, "\nimages (context %s):\n", r.Context)
+	if r.ImagesError != "" {
+		fmt.Fprintf(out, "  (%s)\n", r.ImagesError)
+		return nil
+	}
+	w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "  NAMESPACE\tWORKLOAD\tCONTAINER\tIMAGE")
+	for _, img := range r.Images {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", img.Namespace, img.Workload, img.Container, img.Image)
+	}
+	return w.Flush()
 }
@@ -0,0 +1,69 @@
+#!/usr/bin/env bash
+# Copyright 2024 Nokia
+# Licensed under the Apache License 2.0
+# SPDX-License-Identifier: Apache-2.0
+
+# Checks that the CRD schemas bundled in pkg/validate/crds are a subset of
+# the CRDs the kubenet lab material installs at the pinned ref: every field,
+# type, enum and required field of a bundled schema must exist upstream.
+# Requires curl, yq and jq.
+
+set -o errexit -o nounset -o pipefail
+
+KUBENET_REF=${KUBENET_REF:-v0.0.1}
+SOURCE=${SOURCE:-https://raw.githubusercontent.com/kubenet-dev/kubenet/${KUBENET_REF}/artifacts/out}
+ARTIFACTS=${ARTIFACTS:-kuid-server.yaml kuidapps.yaml}
+CRDS=$(cd "$(dirname "$0")/../pkg/validate/crds" && pwd)
+
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+# tojson prints the yaml documents on stdin as json, one per line, with
+# either the go (mikefarah) or the python (kislyuk) yq.
+tojson() {
+	if yq --version 2>&1 | grep -q mikefarah; then
+		yq -o=json -I=0 '.'
+	else
+		yq -c '.'
+	fi
+}
+
+# schema prints a line per schema node of the CRDs on stdin. The metadata
+# and status of the resources are not validated and skipped.
+schema() {
+	tojson |
+		jq -r '
+			select(.kind == "CustomResourceDefinition") |
+			.metadata.name as $crd | .spec.versions[] | .name as $version |
+			.schema.openAPIV3Schema | path(.. | objects) as $p |
+			($p | map(tostring) | join(".")) as $path |
+			select($path | test("^properties\\.(metadata|status)(\\.|$)") | not) |
+			getpath($p) | select((.type | type) == "string") |
+			"\($crd) \($version) \($path) type=\(.type) format=\(.format // "") enum=\((.enum // []) | join(","))",
+			(.required // [] | .[] as $r | "\($crd) \($version) \($path) required=\($r)")
+		'
+}
+
+for artifact in $ARTIFACTS; do
+	curl -fsSL "$SOURCE/$artifact"
+	echo "---"
+done | schema | sort -u >"$tmp/upstream"
+
+for crd in "$CRDS"/*.yaml; do
+	cat "$crd"
+	echo "---"
+done | schema | sort -u >"$tmp/bundled"
+
+for crd in $(cut -d' ' -f1 "$tmp/bundled" | sort -u); do
+	if ! grep -q "^$crd " "$tmp/upstream"; then
+		echo "crd $crd is not installed by kubenet $KUBENET_REF" >&2
+		exit 1
+	fi
+done
+
+if ! comm -23 "$tmp/bundled" "$tmp/upstream" >"$tmp/diff" || [ -s "$tmp/diff" ]; then
+	echo "the bundled crd schemas differ from kubenet $KUBENET_REF:" >&2
+	cat "$tmp/diff" >&2
+	exit 1
+fi
+echo "the bundled crd schemas are a subset of kubenet $KUBENET_REF"
@@ -11,7 +11,7 @@
 : ${PROJECT_NAME:="kubenetctl"} # if project name does not match binary name
 : ${USE_SUDO:="true"}
 : ${USE_PKG:="false"} # default --use-pkg flag value. will use package installation by default unless the default is changed to false
-: ${VERIFY_CHECKSUM:="false"}
+: ${VERIFY_CHECKSUM:="true"}
 : ${BIN_INSTALL_DIR:="/usr/local/bin"}
 : ${REPO_NAME:="kubenet-dev/kubenetctl"}
 : ${REPO_URL:="https://github.com/$REPO_NAME"}
@@ -160,7 +160,7 @@ downloadFile() {
     fi
     ARCHIVE="${PROJECT_NAME}_${TAG_WO_VER}_${OS}_${ARCH}.${EXT}"
     DOWNLOAD_URL="${REPO_URL}/releases/download/${TAG}/${ARCHIVE}"
-    CHECKSUM_URL="${REPO_URL}/releases/download/${TAG}/checksums.txt"
+    CHECKSUM_URL="${REPO_URL}/releases/download/${TAG}/${PROJECT_NAME}_checksums.txt"
     TMP_FILE="$TMP_ROOT/$ARCHIVE"
     SUM_FILE="$TMP_ROOT/checksums.txt"
     echo "Downloading $DOWNLOAD_URL"
@@ -174,9 +174,9 @@ downloadFile() {
 
     # verify downloaded file
     if [ $VERIFY_CHECKSUM == "true" ]; then
-        local sum=$(openssl sha1 -sha256 ${TMP_FILE} | awk '{print $2}')
-        local expected_sum=$(cat ${SUM_FILE} | grep -i $ARCHIVE | awk '{print $1}')
-        if [ "$sum" != "$expected_sum" ]; then
+        local sum=$(openssl sha1 -sha256 ${TMP_FILE} | awk '{print $NF}')
+        local expected_sum=$(awk -v f="$ARCHIVE" '$2 == f {print $1}' ${SUM_FILE})
+        if [ -z "$expected_sum" ] || [ "$sum" != "$expected_sum" ]; then
             echo "SHA sum of ${TMP_FILE} does not match. Aborting."
             exit 1
         fi
@@ -239,7 +239,7 @@ help() {
     echo -e "\te.g. --version v0.1.1"
     echo -e "\t[--use-pkg]  ->> install from deb/rpm packages"
     echo -e "\t[--no-sudo]  ->> install without sudo"
-    echo -e "\t[--verify-checksum]  ->> verify checksum of the downloaded file"
+    echo -e "\t[--skip-checksum]  ->> do not verify the checksum of the downloaded file"
 }
 
 # removes temporary directory used to download artefacts
@@ -275,6 +275,9 @@ while [[ $# -gt 0 ]]; do
     '--verify-checksum')
         VERIFY_CHECKSUM="true"
         ;;
+    '--skip-checksum')
+        VERIFY_CHECKSUM="false"
+        ;;
     '--use-pkg')
         USE_PKG="true"
         ;;
@@ -25,6 +25,7 @@ import (
 
 	"github.com/henderiw/logger/log"
 	"github.com/kubenet-dev/kubenetctl/commands"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
 )
 
 func main() {
@@ -47,8 +48,12 @@ func runMain() int {
 
 	if err := cmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(cmd.ErrOrStderr(), "%s \n", err.Error())
+		if ctx.Err() != nil {
+			// the command may not wrap the context error
+			return exitcode.Interrupted
+		}
 		cancel()
-		return 1
+		return exitcode.For(err)
 	}
 	return 0
 }
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cast writes and plays asciicast v2 recordings, see
+// https://docs.asciinema.org/manual/asciicast/v2/.
+package cast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	Version = 2
+
+	EventOutput = "o"
+)
+
+// Header is the first line of an asciicast file.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is terminal output recorded at a time relative to the start.
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+func (r Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{r.Time, r.Type, r.Data})
+}
+
+func (r *Event) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("invalid event %s, expected [time, type, data]", b)
+	}
+	if err := json.Unmarshal(raw[0], &r.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &r.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &r.Data)
+}
+
+// NewHeader returns a header with the size of the terminal.
+func NewHeader(title string) Header {
+	width, height := TerminalSize(os.Stdout)
+	return Header{
+		Version:   Version,
+		Width:     width,
+		Height:    height,
+		Timestamp: time.Now().Unix(),
+		Title:     title,
+		Env: map[string]string{
+			"SHELL": os.Getenv("SHELL"),
+			"TERM":  os.Getenv("TERM"),
+		},
+	}
+}
+
+// TerminalSize returns the size of the terminal f is attached to, or 80x24
+// when it is not a terminal.
+func TerminalSize(f *os.File) (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// Writer records everything written to it as output events.
+type Writer struct {
+	m       sync.Mutex
+	enc     *json.Encoder
+	start   time.Time
+	pending []byte
+}
+
+// NewWriter writes the header to w and returns a writer for the events.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(h); err != nil {
+		return nil, fmt.Errorf("cannot write cast header: %w", err)
+	}
+	return &Writer{enc: enc, start: time.Now()}, nil
+}
+
+func (r *Writer) Write(p []byte) (int, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	data := append(r.pending, p...)
+	// keep an incomplete utf-8 sequence at the end for the next write, json
+	// would replace it with the replacement character
+	n := len(data)
+	for i := 1; i < utf8.UTFMax && i <= n; i++ {
+		if utf8.RuneStart(data[n-i]) {
+			if !utf8.FullRune(data[n-i:]) {
+				n -= i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte{}, data[n:]...)
+	if n == 0 {
+		return len(p), nil
+	}
+
+	// the output is not written to a tty, which translates a newline to
+	// carriage return + newline, players expect the tty output
+	out := strings.ReplaceAll(string(data[:n]), "\r\n", "\n")
+	e := Event{
+		Time: time.Since(r.start).Seconds(),
+		Type: EventOutput,
+		Data: strings.ReplaceAll(out, "\n", "\r\n"),
+	}
+	if err := r.enc.Encode(e); err != nil {
+		return 0, fmt.Errorf("cannot write cast event: %w", err)
+	}
+	return len(p), nil
+}
+
+// Read reads an asciicast v2 recording.
+func Read(rd io.Reader) (Header, []Event, error) {
+	s := bufio.NewScanner(rd)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	h := Header{}
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return h, nil, err
+		}
+		return h, nil, fmt.Errorf("empty cast file")
+	}
+	if err := json.Unmarshal(s.Bytes(), &h); err != nil {
+		return h, nil, fmt.Errorf("invalid cast header: %w", err)
+	}
+	if h.Version != Version {
+		return h, nil, fmt.Errorf("unsupported asciicast version %d, expected %d", h.Version, Version)
+	}
+	events := []Event{}
+	line := 1
+	for s.Scan() {
+		line++
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		e := Event{}
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return h, nil, fmt.Errorf("invalid cast event on line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return h, events, s.Err()
+}
+
+// PlayOptions control the playback speed.
+type PlayOptions struct {
+	// Speed multiplies the playback speed.
+	Speed float64
+	// IdleLimit caps the pauses between events, 0 keeps the recorded
+	// pauses.
+	IdleLimit time.Duration
+}
+
+// Play writes the output events to w with the recorded timing.
+func Play(ctx context.Context, w io.Writer, events []Event, opts PlayOptions) error {
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+	var last float64
+	for _, e := range events {
+		delay := time.Duration((e.Time - last) / opts.Speed * float64(time.Second))
+		last = e.Time
+		if opts.IdleLimit > 0 && delay > opts.IdleLimit {
+			delay = opts.IdleLimit
+		}
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		if e.Type != EventOutput {
+			continue
+		}
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
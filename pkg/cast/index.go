@@ -0,0 +1,101 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cast
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+)
+
+const (
+	// IndexFileName is the name of the file in the state directory of a
+	// profile that lists the recordings made with --record.
+	IndexFileName = "recordings"
+
+	// maxIndexed is the number of recordings the index keeps.
+	maxIndexed = 50
+)
+
+// IndexFile returns the recordings index of the current profile.
+func IndexFile() string {
+	return filepath.Join(config.CurrentProfile().StateDir(), IndexFileName)
+}
+
+// Remember adds the recording to the index, so replay can complete it.
+func Remember(index, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	paths, err := readIndex(index)
+	if err != nil {
+		return err
+	}
+	kept := []string{}
+	for _, p := range paths {
+		if p != abs {
+			kept = append(kept, p)
+		}
+	}
+	kept = append(kept, abs)
+	if len(kept) > maxIndexed {
+		kept = kept[len(kept)-maxIndexed:]
+	}
+	if err := os.MkdirAll(filepath.Dir(index), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(index, []byte(strings.Join(kept, "\n")+"\n"), 0600)
+}
+
+// Recordings returns the recordings of the index that still exist, the
+// latest first.
+func Recordings(index string) ([]string, error) {
+	paths, err := readIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	recordings := []string{}
+	for i := len(paths) - 1; i >= 0; i-- {
+		if _, err := os.Stat(paths[i]); err == nil {
+			recordings = append(recordings, paths[i])
+		}
+	}
+	return recordings, nil
+}
+
+func readIndex(index string) ([]string, error) {
+	f, err := os.Open(index)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	paths := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, s.Err()
+}
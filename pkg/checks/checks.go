@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checks builds the commands of the verification checks of the
+// runbooks.
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+)
+
+// attemptTimeout bounds a single attempt of a kubectl wait, the check is
+// retried until the deadline of the verification.
+const attemptTimeout = "--timeout=10s"
+
+// Ready passes when the objects have a Ready condition that is True, all
+// objects of the resource when no names are provided.
+func Ready(resource string, names ...string) string {
+	if len(names) == 0 {
+		return fmt.Sprintf("kubectl wait --for=condition=Ready %s --all --all-namespaces %s", resource, attemptTimeout)
+	}
+	objs := make([]string, 0, len(names))
+	for _, name := range names {
+		objs = append(objs, resource+"/"+name)
+	}
+	return fmt.Sprintf("kubectl wait --for=condition=Ready %s %s", strings.Join(objs, " "), attemptTimeout)
+}
+
+// NetworkReady passes when the network of the topology is Ready.
+func NetworkReady(topology, name string) string {
+	return Ready(network.Resource, topology+"."+name)
+}
+
+// Exists passes when the resource has at least one object.
+func Exists(resource string) string {
+	return fmt.Sprintf("kubectl get %s --all-namespaces -o name | grep -q .", resource)
+}
+
+// DeploymentsAvailable passes when all deployments are available.
+func DeploymentsAvailable() string {
+	return "kubectl wait --for=condition=Available deployments --all --all-namespaces " + attemptTimeout
+}
+
+// NodesReady passes when the nodes of the kind cluster are Ready.
+func NodesReady() string {
+	return "kubectl wait --for=condition=Ready nodes --all " + attemptTimeout
+}
+
+// LabRunning passes when the containers of the containerlab lab run.
+func LabRunning(lab string) string {
+	return fmt.Sprintf("test -n \"$(docker ps -q --filter label=containerlab=%s)\"", lab)
+}
+
+// BGPEstablished passes when every SR Linux node of the lab has BGP
+// sessions and all of them are established.
+func BGPEstablished(lab string) string {
+	return fmt.Sprintf(`n=0; for c in $(docker ps --format '{{.Names}}' --filter label=containerlab=%s); do `+
+		`docker exec "$c" sh -c 'command -v sr_cli' >/dev/null 2>&1 || continue; n=$((n+1)); `+
+		`out=$(docker exec "$c" sr_cli -d "show network-instance default protocols bgp neighbor") || exit 1; `+
+		`echo "$out" | grep -qi established || { echo "$c: no established bgp session"; exit 1; }; `+
+		`echo "$out" | grep -Eqi " (active|connect|idle|opensent|openconfirm) " && { echo "$c: bgp sessions not established"; exit 1; }; `+
+		`done; [ "$n" -gt 0 ] || { echo "no SR Linux nodes of lab %s are running"; exit 1; }`, lab, lab)
+}
+
+// Ping passes when the client container of the containerlab lab reaches
+// the target address, e.g. a client on the other side of an overlay.
+func Ping(lab, client, target string) string {
+	return fmt.Sprintf("docker exec clab-%s-%s ping -c1 -W2 %s", lab, client, target)
+}
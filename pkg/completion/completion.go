@@ -0,0 +1,158 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package completion provides dynamic shell completion of values that are
+// looked up in the cluster or the local state. Kubeconfig contexts are not
+// completed: kubenet has no context flag, the profile selects the kubeconfig
+// and the kind context of the lab.
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/cast"
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"github.com/spf13/cobra"
+)
+
+// timeout bounds the cluster lookups, completion must stay responsive when
+// the cluster is not reachable.
+const timeout = 3 * time.Second
+
+func lookupContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func flagValue(cmd *cobra.Command, name string) string {
+	f := cmd.Flag(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// Topologies completes the topology names in the cluster.
+func Topologies(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := lookupContext(cmd)
+	defer cancel()
+	names, err := network.ListTopologies(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Networks completes the network names in the namespace of the --namespace
+// flag, for commands that take a single network argument.
+func Networks(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := lookupContext(cmd)
+	defer cancel()
+	nets, err := network.List(ctx, flagValue(cmd, "namespace"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(nets))
+	for _, n := range nets {
+		names = append(names, n.Metadata.Name+"\t"+string(n.Type()))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Namespaces completes the namespaces in the cluster.
+func Namespaces(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := lookupContext(cmd)
+	defer cancel()
+	b, err := kubectl.Output(ctx, "get", "namespaces", "-o", "name")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := []string{}
+	for _, line := range strings.Fields(string(b)) {
+		names = append(names, strings.TrimPrefix(line, "namespace/"))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Interfaces completes node:endpoint of the topology of the --topology
+// flag.
+func Interfaces(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := lookupContext(cmd)
+	defer cancel()
+	topo, err := network.GetTopology(ctx, flagValue(cmd, "topology"))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	itfces := []string{}
+	for _, node := range topo.NodeNames() {
+		for ep := range topo.Nodes[node] {
+			itfces = append(itfces, node+":"+ep)
+		}
+	}
+	return itfces, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Profiles completes the profiles that have been used.
+func Profiles(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Recordings completes the recordings made with --record in the current
+// profile, the latest first, and falls back to the .cast files when none
+// matches.
+func Recordings(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	recordings, _ := cast.Recordings(cast.IndexFile())
+	cwd, _ := os.Getwd()
+	matches := []string{}
+	for _, p := range recordings {
+		if rel, err := filepath.Rel(cwd, p); err == nil && !strings.HasPrefix(rel, "..") {
+			p = rel
+		}
+		if strings.HasPrefix(p, toComplete) {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) == 0 {
+		return []string{"cast"}, cobra.ShellCompDirectiveFilterFileExt
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Values completes a fixed set of values.
+func Values(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+)
+
+// toolTimeout bounds the version query of a single tool.
+const toolTimeout = 5 * time.Second
+
+// Tool is an external tool the runbooks depend on.
+type Tool struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Image is a container image of a workload in the cluster.
+type Image struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Workload  string `json:"workload" yaml:"workload"`
+	Container string `json:"container" yaml:"container"`
+	Image     string `json:"image" yaml:"image"`
+}
+
+var versionRegexp = regexp.MustCompile(`v?[0-9]+\.[0-9]+\.[0-9]+[^\s,]*`)
+
+// tools lists the tools and the arguments that print their version.
+var tools = []struct {
+	name string
+	args []string
+}{
+	{name: "kind", args: []string{"version"}},
+	{name: "containerlab", args: []string{"version"}},
+	{name: kubectl.Binary, args: []string{"version", "--client"}},
+	{name: "docker", args: []string{"--version"}},
+}
+
+// Tools returns the versions of the tools the runbooks use. A tool that is
+// not installed is reported with an error.
+func Tools(ctx context.Context) []Tool {
+	result := make([]Tool, 0, len(tools))
+	for _, t := range tools {
+		result = append(result, toolVersion(ctx, t.name, t.args...))
+	}
+	return result
+}
+
+func toolVersion(ctx context.Context, name string, args ...string) Tool {
+	t := Tool{Name: name}
+	ctx, cancel := context.WithTimeout(ctx, toolTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if _, lookErr := exec.LookPath(name); lookErr != nil {
+			t.Error = "not installed"
+		} else {
+			t.Error = err.Error()
+		}
+		return t
+	}
+	if t.Version = versionRegexp.FindString(string(out)); t.Version == "" {
+		t.Error = fmt.Sprintf("unknown version output %q", strings.TrimSpace(string(out)))
+	}
+	return t
+}
+
+// systemNamespaces are not installed by kubenet.
+var systemNamespaces = map[string]bool{
+	"kube-system":        true,
+	"kube-public":        true,
+	"kube-node-lease":    true,
+	"local-path-storage": true,
+}
+
+type workloadList struct {
+	Items []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec struct {
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Name  string `json:"name"`
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// Images returns the images of the kubenet components installed in the
+// cluster.
+func Images(ctx context.Context) ([]Image, error) {
+	l := workloadList{}
+	if err := kubectl.Get(ctx, &l, "deployments,statefulsets,daemonsets", "--all-namespaces"); err != nil {
+		return nil, err
+	}
+	images := []Image{}
+	for _, item := range l.Items {
+		if systemNamespaces[item.Metadata.Namespace] {
+			continue
+		}
+		for _, c := range item.Spec.Template.Spec.Containers {
+			images = append(images, Image{
+				Namespace: item.Metadata.Namespace,
+				Workload:  strings.ToLower(item.Kind) + "/" + item.Metadata.Name,
+				Container: c.Name,
+				Image:     c.Image,
+			})
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Namespace != images[j].Namespace {
+			return images[i].Namespace < images[j].Namespace
+		}
+		return images[i].Workload < images[j].Workload
+	})
+	return images, nil
+}
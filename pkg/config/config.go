@@ -0,0 +1,405 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config defines the settings of kubenetctl. Settings are read from
+// flags, KUBENETCTL_ environment variables and the config file, in that
+// order of precedence.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigFileSubDir = "kubenet"
+	ConfigFileName   = "kubenet.yaml"
+	EnvPrefix        = "KUBENETCTL"
+
+	KeyKubenetRef  = "kubenet-ref"
+	KeyClusterName = "cluster-name"
+	KeyTopology    = "topology"
+	KeyShell       = "shell"
+	KeyOutput      = "output"
+	KeyColor       = "color"
+	KeyTimeout     = "timeout"
+	KeyStepDelay   = "step-delay"
+	KeyUpdateCheck = "update-check"
+	KeyReleaseURL  = "release-url"
+	KeyHost        = "host"
+	KeyIdentity    = "identity-file"
+	KeyKnownHosts  = "known-hosts"
+	KeyExecMode    = "exec-mode"
+	KeyToolbox     = "toolbox-image"
+	KeyEnvAllow    = "env-allowlist"
+
+	// EnvNoUpdateCheck disables the update check when set to a true value.
+	EnvNoUpdateCheck = EnvPrefix + "_NO_UPDATE_CHECK"
+)
+
+// Exec modes of the runbook steps.
+const (
+	ExecModeHost      = "host"
+	ExecModeContainer = "container"
+)
+
+// Type is the type of the value of a setting.
+type Type string
+
+const (
+	TypeString   Type = "string"
+	TypeBool     Type = "bool"
+	TypeDuration Type = "duration"
+)
+
+// Key describes a setting.
+type Key struct {
+	Name        string
+	Type        Type
+	Default     string
+	Description string
+	// Enum restricts a string setting to these values.
+	Enum []string
+	// check validates a value beyond its type.
+	check func(string) error
+}
+
+// Keys is the config schema.
+var Keys = []Key{
+	{Name: KeyKubenetRef, Type: TypeString, Default: "v0.0.1", Description: "git ref of the kubenet lab material the runbooks apply"},
+	{Name: KeyProfile, Type: TypeString, Default: DefaultProfile, Description: "profile of the lab, namespaces the cluster, lab, docker network and run state", check: ValidateProfileName},
+	{Name: KeyClusterName, Type: TypeString, Default: "kubenet", Description: "name of the kind cluster and containerlab lab, suffixed with the profile name"},
+	{Name: KeyTopology, Type: TypeString, Default: "topo3nodesrl", Description: "topology the networks are created in"},
+	{Name: KeyShell, Type: TypeString, Default: "bash", Description: "shell used to execute the commands"},
+	{Name: KeyOutput, Type: TypeString, Default: "text", Description: "output format of the list commands", Enum: []string{"text", "json", "yaml"}},
+	{Name: KeyColor, Type: TypeBool, Default: "true", Description: "colorize the output"},
+	{Name: KeyTimeout, Type: TypeDuration, Default: "5m", Description: "time to wait for resources to be ready or deleted"},
+	{Name: KeyStepDelay, Type: TypeDuration, Default: "0s", Description: "pause between the steps of a runbook"},
+	{Name: KeyUpdateCheck, Type: TypeBool, Default: "true", Description: "check once a day whether a newer kubenet release is available"},
+	{Name: KeyReleaseURL, Type: TypeString, Default: "", Description: "GitHub compatible API the releases are queried from, defaults to the GitHub API"},
+	{Name: KeyHost, Type: TypeString, Default: "", Description: "run the steps and checks of the runbooks on this host over SSH, user@host[:port]"},
+	{Name: KeyIdentity, Type: TypeString, Default: "", Description: "private key to authenticate to the host with, besides the SSH agent"},
+	{Name: KeyExecMode, Type: TypeString, Default: ExecModeHost, Description: "where the steps of the runbooks run: host, or container to run them in the toolbox image", Enum: []string{ExecModeHost, ExecModeContainer}},
+	{Name: KeyToolbox, Type: TypeString, Default: "", Description: "toolbox image of the container exec mode, defaults to the image of the release"},
+	{Name: KeyEnvAllow, Type: TypeString, Default: "", Description: "comma separated variables the commands of the runbooks inherit, all when empty; PATH, HOME and KUBECONFIG are always inherited"},
+	{Name: KeyKnownHosts, Type: TypeString, Default: "", Description: "known hosts file the host key is checked with, defaults to ~/.ssh/known_hosts"},
+}
+
+// GetKey returns the schema of the setting.
+func GetKey(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown config key %q, expected one of %v", name, KeyNames())
+}
+
+// KeyNames returns the names of the settings.
+func KeyNames() []string {
+	names := make([]string, 0, len(Keys))
+	for _, k := range Keys {
+		names = append(names, k.Name)
+	}
+	return names
+}
+
+// Env returns the environment variable of the setting.
+func (r Key) Env() string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(r.Name, "-", "_"))
+}
+
+// Validate checks that the value can be parsed as the type of the setting.
+func (r Key) Validate(value string) error {
+	switch r.Type {
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s: invalid bool %q", r.Name, value)
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s: invalid duration %q, e.g. 30s or 5m", r.Name, value)
+		}
+	case TypeString:
+		if r.check != nil {
+			return r.check(value)
+		}
+		if len(r.Enum) > 0 {
+			for _, e := range r.Enum {
+				if e == value {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: %q is not one of %v", r.Name, value, r.Enum)
+		}
+	}
+	return nil
+}
+
+// DefaultFile is the config file used when --config is not set.
+func DefaultFile() string {
+	return filepath.Join(xdg.ConfigHome, ConfigFileSubDir, ConfigFileName)
+}
+
+// Init sets up viper with the defaults, environment variables and the
+// config file. Flags that share the name of a setting are bound to it.
+func Init(file string, flags *pflag.FlagSet) error {
+	for _, k := range Keys {
+		viper.SetDefault(k.Name, k.Default)
+		if f := flags.Lookup(k.Name); f != nil {
+			if err := viper.BindPFlag(k.Name, f); err != nil {
+				return err
+			}
+		}
+	}
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+
+	if file == "" {
+		file = DefaultFile()
+	}
+	viper.SetConfigFile(file)
+	viper.SetConfigType("yaml")
+	if err := viper.ReadInConfig(); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot read config file %s: %w", file, err)
+	}
+	return nil
+}
+
+// Source tells where the value of a setting comes from.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// SourceOf returns the source of the effective value of the setting.
+func SourceOf(key string, flags *pflag.FlagSet) Source {
+	k, err := GetKey(key)
+	if err != nil {
+		return SourceDefault
+	}
+	if f := flags.Lookup(key); f != nil && f.Changed {
+		return SourceFlag
+	}
+	if _, ok := os.LookupEnv(k.Env()); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// File is the content of a config file.
+type File struct {
+	Path     string
+	Settings map[string]string
+}
+
+// ReadFile reads the config file, a missing file has no settings.
+func ReadFile(path string) (*File, error) {
+	f := &File{Path: path, Settings: map[string]string{}}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &f.Settings); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if f.Settings == nil {
+		f.Settings = map[string]string{}
+	}
+	return f, nil
+}
+
+// Validate checks the keys and values of the file against the schema.
+func (r *File) Validate() error {
+	errs := []string{}
+	for _, name := range r.Names() {
+		k, err := GetKey(name)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := k.Validate(r.Settings[name]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config file %s:\n  %s", r.Path, strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// Names returns the sorted keys set in the file.
+func (r *File) Names() []string {
+	names := make([]string, 0, len(r.Settings))
+	for name := range r.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes the file, the directory is created when it does not exist.
+func (r *File) Write() error {
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0700); err != nil {
+		return err
+	}
+	// write typed values so that e.g. a bool is not quoted
+	settings := make(map[string]any, len(r.Settings))
+	for name, value := range r.Settings {
+		settings[name] = value
+		if k, err := GetKey(name); err == nil && k.Type == TypeBool {
+			if b, err := strconv.ParseBool(value); err == nil {
+				settings[name] = b
+			}
+		}
+	}
+	b, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.Path, b, 0600)
+}
+
+// KubenetRef returns the git ref of the kubenet lab material.
+func KubenetRef() string {
+	return viper.GetString(KeyKubenetRef)
+}
+
+// KubenetURL returns the url of a file in the kubenet lab material.
+func KubenetURL(path string) string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/kubenet-dev/kubenet/%s/%s", KubenetRef(), path)
+}
+
+// ClusterName returns the name of the kind cluster of the current profile.
+func ClusterName() string {
+	return CurrentProfile().ClusterName()
+}
+
+// Topology returns the topology the networks are created in.
+func Topology() string {
+	return viper.GetString(KeyTopology)
+}
+
+// Output returns the output format of the list and describe commands.
+func Output() string {
+	return viper.GetString(KeyOutput)
+}
+
+// Timeout returns the time to wait for resources.
+func Timeout() time.Duration {
+	return viper.GetDuration(KeyTimeout)
+}
+
+// UpdateCheck returns true when kubenet checks for newer releases.
+func UpdateCheck() bool {
+	if v, ok := os.LookupEnv(EnvNoUpdateCheck); ok {
+		if b, err := strconv.ParseBool(v); err != nil || b {
+			return false
+		}
+	}
+	return viper.GetBool(KeyUpdateCheck)
+}
+
+// ReleaseURL returns the API the releases are queried from, "" is the
+// GitHub API.
+func ReleaseURL() string {
+	return viper.GetString(KeyReleaseURL)
+}
+
+// Host returns the host the runbooks run on, "" is this machine.
+func Host() string {
+	return viper.GetString(KeyHost)
+}
+
+// ExecMode returns where the steps of the runbooks run.
+func ExecMode() string {
+	return viper.GetString(KeyExecMode)
+}
+
+// EnvAllowlist returns the variables the commands inherit in allowlist
+// mode, nil when the commands inherit all variables.
+func EnvAllowlist() []string {
+	var names []string
+	for _, name := range strings.Split(viper.GetString(KeyEnvAllow), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CacheFile returns the path of a file in the kubenet cache directory.
+func CacheFile(name string) string {
+	return filepath.Join(xdg.CacheHome, ConfigFileSubDir, name)
+}
+
+// Describe documents the settings, their environment variables and
+// defaults.
+func Describe() string {
+	b := &strings.Builder{}
+	b.WriteString("Settings are read from flags, environment variables and the config file,\n")
+	fmt.Fprintf(b, "in that order of precedence. The config file defaults to %s.\n\n", DefaultFile())
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tENV\tDESCRIPTION")
+	for _, k := range Keys {
+		desc := k.Description
+		if len(k.Enum) > 0 {
+			desc = fmt.Sprintf("%s %v", desc, k.Enum)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", k.Name, k.Type, k.Default, k.Env(), desc)
+	}
+	w.Flush()
+	fmt.Fprintf(b, "\nSet %s=1 to disable the update check.\n", EnvNoUpdateCheck)
+	return b.String()
+}
+
+// FileUsed returns the path of the config file, which may not exist yet.
+func FileUsed() string {
+	if f := viper.ConfigFileUsed(); f != "" {
+		return f
+	}
+	return DefaultFile()
+}
+
+// Get returns the effective value of a setting.
+func Get(key string) string {
+	return viper.GetString(key)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestUpdateCheck(t *testing.T) {
+	tests := map[string]struct {
+		env     string
+		setting bool
+		want    bool
+	}{
+		"enabled":                   {setting: true, want: true},
+		"disabled in the config":    {setting: false, want: false},
+		"opted out with env":        {env: "1", setting: true, want: false},
+		"opted out with env true":   {env: "true", setting: true, want: false},
+		"env false keeps the check": {env: "false", setting: true, want: true},
+		"env false and config off":  {env: "false", setting: false, want: false},
+		"invalid env opts out":      {env: "yes please", setting: true, want: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// restores the variable of the environment the test runs in
+			t.Setenv(EnvNoUpdateCheck, tc.env)
+			if tc.env == "" {
+				os.Unsetenv(EnvNoUpdateCheck)
+			}
+			viper.Set(KeyUpdateCheck, tc.setting)
+			t.Cleanup(viper.Reset)
+			if got := UpdateCheck(); got != tc.want {
+				t.Errorf("UpdateCheck() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
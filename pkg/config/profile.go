@@ -0,0 +1,174 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/viper"
+)
+
+const (
+	KeyProfile = "profile"
+
+	// DefaultProfile keeps the names kubenet used before profiles existed.
+	DefaultProfile = "default"
+
+	defaultDockerNetwork = "kind"
+)
+
+var profileName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// Profile namespaces a lab, so several labs can run side by side on one
+// host.
+type Profile struct {
+	Name string
+}
+
+// CurrentProfile returns the profile selected with --profile, the
+// environment or the config file.
+func CurrentProfile() Profile {
+	name := viper.GetString(KeyProfile)
+	if name == "" {
+		name = DefaultProfile
+	}
+	return Profile{Name: name}
+}
+
+// ValidateProfileName checks that the name can be used in cluster, network
+// and lab names.
+func ValidateProfileName(name string) error {
+	if !profileName.MatchString(name) || len(name) > 32 {
+		return fmt.Errorf("invalid profile name %q, use at most 32 lower case letters, digits and dashes", name)
+	}
+	return nil
+}
+
+func (r Profile) IsDefault() bool {
+	return r.Name == DefaultProfile
+}
+
+func (r Profile) suffix() string {
+	if r.IsDefault() {
+		return ""
+	}
+	return "-" + r.Name
+}
+
+// ClusterName returns the name of the kind cluster of the profile.
+func (r Profile) ClusterName() string {
+	return viper.GetString(KeyClusterName) + r.suffix()
+}
+
+// LabName returns the name of the containerlab lab of the profile.
+func (r Profile) LabName() string {
+	return viper.GetString(KeyClusterName) + r.suffix()
+}
+
+// DockerNetwork returns the docker network kind and containerlab share.
+func (r Profile) DockerNetwork() string {
+	return defaultDockerNetwork + r.suffix()
+}
+
+// KubeContext returns the kubeconfig context kind creates for the cluster.
+func (r Profile) KubeContext() string {
+	return "kind-" + r.ClusterName()
+}
+
+// Dir is the directory with the files of the profile.
+func (r Profile) Dir() string {
+	return filepath.Join(xdg.ConfigHome, ConfigFileSubDir, "profiles", r.Name)
+}
+
+// Kubeconfig returns the kubeconfig of the profile. The default profile
+// uses the kubeconfig of the user.
+func (r Profile) Kubeconfig() string {
+	if r.IsDefault() {
+		return ""
+	}
+	return filepath.Join(r.Dir(), "kubeconfig")
+}
+
+// RemoteKubeconfig returns the kubeconfig of the profile on a host the
+// commands run on over SSH, relative to the home directory there. The
+// default profile uses the kubeconfig of the remote user.
+func (r Profile) RemoteKubeconfig() string {
+	if r.IsDefault() {
+		return ""
+	}
+	return path.Join(".config", ConfigFileSubDir, "profiles", r.Name, "kubeconfig")
+}
+
+// StateDir is the directory with the run state of the profile.
+func (r Profile) StateDir() string {
+	return filepath.Join(xdg.StateHome, ConfigFileSubDir, r.Name)
+}
+
+// Activate points kubectl and kind to the kubeconfig of the profile. The
+// commands on a remote host get RemoteKubeconfig instead.
+func (r Profile) Activate() error {
+	if r.IsDefault() {
+		return nil
+	}
+	if err := os.MkdirAll(r.Dir(), 0700); err != nil {
+		return err
+	}
+	return os.Setenv("KUBECONFIG", r.Kubeconfig())
+}
+
+// ListProfiles returns the default profile and the profiles that have been
+// used.
+func ListProfiles() ([]string, error) {
+	names := map[string]bool{DefaultProfile: true}
+	for _, dir := range []string{
+		filepath.Join(xdg.ConfigHome, ConfigFileSubDir, "profiles"),
+		filepath.Join(xdg.StateHome, ConfigFileSubDir),
+	} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				names[e.Name()] = true
+			}
+		}
+	}
+	profiles := make([]string, 0, len(names))
+	for name := range names {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// Delete removes the files and run state of the profile.
+func (r Profile) Delete() error {
+	if err := os.RemoveAll(r.Dir()); err != nil {
+		return err
+	}
+	return os.RemoveAll(r.StateDir())
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+)
+
+// Format is the output format of the rendered documentation.
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatMarkdown, FormatHTML:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unsupported format %q, expected %s or %s", s, FormatMarkdown, FormatHTML)
+}
+
+// Ext returns the file extension of the format.
+func (r Format) Ext() string {
+	return "." + string(r)
+}
+
+// Page is a rendered runbook in the site index.
+type Page struct {
+	Name  string
+	Title string
+	File  string
+}
+
+type guide struct {
+	Title       string
+	Description []string
+	Steps       []guideStep
+	Checks      []string
+}
+
+type guideStep struct {
+	Number  int
+	Title   string
+	Text    []string
+	Command string
+	Plan    string
+	Expect  string
+}
+
+func newGuide(rb *run.Run) guide {
+	g := guide{Title: rb.Title(), Description: rb.Description()}
+	for i, s := range rb.Steps() {
+		gs := guideStep{
+			Number: i + 1,
+			// the command is printed the same way as the runner shows it
+			Command: strings.Join(s.Command, " \\\n    "),
+			Plan:    s.Plan,
+			Expect:  s.Expect,
+		}
+		if len(s.Text) > 0 {
+			gs.Title = s.Text[0]
+			gs.Text = s.Text[1:]
+		}
+		g.Steps = append(g.Steps, gs)
+	}
+	for _, c := range rb.Checks() {
+		g.Checks = append(g.Checks, strings.Join(c.Text, " "))
+	}
+	return g
+}
+
+// Render writes a step-by-step guide of the runbook.
+func Render(w io.Writer, f Format, rb *run.Run) error {
+	g := newGuide(rb)
+	if f == FormatHTML {
+		return htmlGuide.Execute(w, g)
+	}
+	return writeMarkdown(w, g)
+}
+
+// RenderIndex writes the index of the rendered runbooks.
+func RenderIndex(w io.Writer, f Format, pages []Page) error {
+	if f == FormatHTML {
+		return htmlIndex.Execute(w, pages)
+	}
+	b := &strings.Builder{}
+	b.WriteString("# kubenet exercises\n\n")
+	for _, p := range pages {
+		fmt.Fprintf(b, "- [%s](%s) (`kubenet %s`)\n", p.Title, p.File, p.Name)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdown(w io.Writer, g guide) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# %s\n", g.Title)
+	for _, d := range g.Description {
+		fmt.Fprintf(b, "\n%s\n", d)
+	}
+	for _, s := range g.Steps {
+		fmt.Fprintf(b, "\n## Step %d: %s\n", s.Number, s.Title)
+		for _, t := range s.Text {
+			fmt.Fprintf(b, "\n%s\n", t)
+		}
+		if s.Command != "" {
+			fmt.Fprintf(b, "\n```bash\n%s\n```\n", s.Command)
+		}
+		if s.Plan != "" {
+			fmt.Fprintf(b, "\n_Host change:_ %s\n", s.Plan)
+		}
+		if s.Expect != "" {
+			fmt.Fprintf(b, "\n**Expected outcome:** %s\n", s.Expect)
+		}
+	}
+	if len(g.Checks) > 0 {
+		b.WriteString("\n## Verify\n\nAfter the steps the runbook checks that:\n\n")
+		for _, c := range g.Checks {
+			fmt.Fprintf(b, "- %s\n", c)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; padding: 0 1em; }
+pre { background: #f4f4f4; padding: 1em; overflow-x: auto; }
+.expect { border-left: 4px solid #2e7d32; padding-left: 1em; }
+.plan { border-left: 4px solid #f9a825; padding-left: 1em; }
+</style>
+</head>
+<body>
+`
+
+var htmlGuide = template.Must(template.New("guide").Funcs(template.FuncMap{"inline": inlineCode}).Parse(htmlHead + `<h1>{{ .Title }}</h1>
+{{ range .Description }}<p>{{ . }}</p>
+{{ end }}
+{{- range .Steps }}
+<h2>Step {{ .Number }}: {{ .Title }}</h2>
+{{ range .Text }}<p>{{ . }}</p>
+{{ end }}
+{{- if .Command }}<pre><code>{{ .Command }}</code></pre>
+{{ end }}
+{{- if .Plan }}<p class="plan"><em>Host change:</em> {{ inline .Plan }}</p>
+{{ end }}
+{{- if .Expect }}<p class="expect"><strong>Expected outcome:</strong> {{ inline .Expect }}</p>
+{{ end }}
+{{- end }}
+{{- if .Checks }}
+<h2>Verify</h2>
+<p>After the steps the runbook checks that:</p>
+<ul>
+{{ range .Checks }}<li>{{ . }}</li>
+{{ end }}</ul>
+{{ end }}</body>
+</html>
+`))
+
+var htmlIndex = template.Must(template.New("index").Parse(strings.Replace(htmlHead, "{{ .Title }}", "kubenet exercises", 1) + `<h1>kubenet exercises</h1>
+<ul>
+{{ range . }}<li><a href="{{ .File }}">{{ .Title }}</a> (<code>kubenet {{ .Name }}</code>)</li>
+{{ end }}</ul>
+</body>
+</html>
+`))
+
+// inlineCode escapes s and renders the markdown `code` spans as code
+// elements.
+func inlineCode(s string) template.HTML {
+	b := &strings.Builder{}
+	for i, part := range strings.Split(s, "`") {
+		if i%2 == 1 {
+			b.WriteString("<code>" + template.HTMLEscapeString(part) + "</code>")
+			continue
+		}
+		b.WriteString(template.HTMLEscapeString(part))
+	}
+	return template.HTML(b.String()) //nolint:gosec // the parts are escaped
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exitcode defines the typed errors of kubenet and the exit codes
+// they map to, so scripts can tell failures apart.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes of kubenet.
+const (
+	OK           = 0
+	Error        = 1
+	Precondition = 3
+	StepFailed   = 4
+	Timeout      = 5
+	Download     = 6
+	VerifyFailed = 7
+	Interrupted  = 130
+)
+
+var exitCodes = []struct {
+	code int
+	desc string
+}{
+	{OK, "success"},
+	{Error, "any other error, e.g. invalid flags or arguments"},
+	{Precondition, "a precondition is not met, e.g. a missing tool or an unknown topology"},
+	{StepFailed, "the command of a runbook step failed"},
+	{Timeout, "an operation did not complete in time"},
+	{Download, "a release or file could not be downloaded"},
+	{VerifyFailed, "the verification checks of an exercise did not pass in time"},
+	{Interrupted, "interrupted by the user"},
+}
+
+// Table documents the exit codes.
+func Table() string {
+	b := &strings.Builder{}
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE\tMEANING")
+	for _, c := range exitCodes {
+		fmt.Fprintf(w, "%d\t%s\n", c.code, c.desc)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// For returns the exit code for the error.
+func For(err error) int {
+	var (
+		stepErr         *StepError
+		preconditionErr *PreconditionError
+		timeoutErr      *TimeoutError
+		downloadErr     *DownloadError
+		verifyErr       *VerifyError
+	)
+	switch {
+	case err == nil:
+		return OK
+	case errors.Is(err, context.Canceled):
+		return Interrupted
+	case errors.As(err, &verifyErr):
+		// checks that did not pass in time are a failed verification
+		return VerifyFailed
+	case errors.As(err, &timeoutErr), errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.As(err, &stepErr):
+		return StepFailed
+	case errors.As(err, &preconditionErr):
+		return Precondition
+	case errors.As(err, &downloadErr):
+		return Download
+	}
+	return Error
+}
+
+// StepError is returned when the command of a runbook step fails.
+type StepError struct {
+	Runbook string
+	Step    int
+	Steps   int
+	Text    string
+	Command string
+	// CommandExitCode is the exit code of the command, -1 when it did not
+	// exit normally.
+	CommandExitCode int
+	Err             error
+}
+
+func (r *StepError) Error() string {
+	return fmt.Sprintf("runbook %q failed at step %d/%d %q: command `%s` exited with code %d",
+		r.Runbook, r.Step, r.Steps, r.Text, r.Command, r.CommandExitCode)
+}
+
+func (r *StepError) Unwrap() error { return r.Err }
+
+// PreconditionError is returned when a command cannot start, e.g. a tool
+// or a resource it depends on is missing.
+type PreconditionError struct {
+	Err error
+}
+
+// Preconditionf returns a PreconditionError.
+func Preconditionf(format string, a ...any) error {
+	return &PreconditionError{Err: fmt.Errorf(format, a...)}
+}
+
+func (r *PreconditionError) Error() string { return r.Err.Error() }
+
+func (r *PreconditionError) Unwrap() error { return r.Err }
+
+// TimeoutError is returned when an operation does not complete in time, a
+// Timeout of 0 is a deadline of the context.
+type TimeoutError struct {
+	Op      string
+	Timeout time.Duration
+	Err     error
+}
+
+func (r *TimeoutError) Error() string {
+	msg := fmt.Sprintf("%s did not complete in time", r.Op)
+	if r.Timeout > 0 {
+		msg = fmt.Sprintf("%s did not complete within %s", r.Op, r.Timeout)
+	}
+	if r.Err == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, r.Err)
+}
+
+func (r *TimeoutError) Unwrap() error { return r.Err }
+
+// DownloadError is returned when a file cannot be downloaded.
+type DownloadError struct {
+	URL string
+	Err error
+}
+
+func (r *DownloadError) Error() string {
+	return fmt.Sprintf("cannot download %s: %s", r.URL, r.Err)
+}
+
+func (r *DownloadError) Unwrap() error { return r.Err }
+
+// VerifyError is returned when checks did not pass before the deadline, it
+// wraps the TimeoutError of the deadline.
+type VerifyError struct {
+	Runbook string
+	Failed  int
+	Timeout time.Duration
+}
+
+func (r *VerifyError) Error() string {
+	return fmt.Sprintf("verification of %q failed: %d check(s) did not pass within %s", r.Runbook, r.Failed, r.Timeout)
+}
+
+func (r *VerifyError) Unwrap() error {
+	return &TimeoutError{Op: fmt.Sprintf("verification of %q", r.Runbook), Timeout: r.Timeout}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFor(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want int
+	}{
+		"nil":              {err: nil, want: OK},
+		"other":            {err: errors.New("boom"), want: Error},
+		"interrupted":      {err: fmt.Errorf("stopped: %w", context.Canceled), want: Interrupted},
+		"deadline":         {err: fmt.Errorf("stopped: %w", context.DeadlineExceeded), want: Timeout},
+		"timeout":          {err: &TimeoutError{Op: "delete", Timeout: time.Second}, want: Timeout},
+		"step":             {err: &StepError{Runbook: "setup", Step: 1, Steps: 2}, want: StepFailed},
+		"precondition":     {err: Preconditionf("kind not found"), want: Precondition},
+		"download":         {err: &DownloadError{URL: "https://example.com", Err: errors.New("404")}, want: Download},
+		"download timeout": {err: &TimeoutError{Op: "download", Err: context.DeadlineExceeded}, want: Timeout},
+		"verify":           {err: &VerifyError{Runbook: "sdc", Failed: 1, Timeout: time.Minute}, want: VerifyFailed},
+		"joined":           {err: errors.Join(errors.New("cleanup failed"), &StepError{}), want: StepFailed},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := For(tc.err); got != tc.want {
+				t.Errorf("For(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyErrorIsTimeout(t *testing.T) {
+	var timeoutErr *TimeoutError
+	err := error(&VerifyError{Runbook: "sdc", Failed: 1, Timeout: time.Minute})
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != time.Minute {
+		t.Fatalf("VerifyError does not wrap the timeout of the deadline: %v", timeoutErr)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+// Binary is the kubectl binary that is executed.
+var Binary = "kubectl"
+
+// Output runs kubectl with the provided arguments and returns stdout.
+func Output(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, Binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, exitcode.Preconditionf("%s not found, it is required to query the cluster", Binary)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("kubectl %s: %s", strings.Join(args, " "), msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Get runs kubectl get with json output and decodes the result into obj.
+func Get(ctx context.Context, obj any, args ...string) error {
+	b, err := Output(ctx, append([]string{"get", "-o", "json"}, args...)...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		return fmt.Errorf("cannot decode kubectl output: %w", err)
+	}
+	return nil
+}
+
+// Manifest is a generated manifest a runbook step applies with kubectl
+// apply -f from a temporary file. The file is written and removed by the
+// setup and cleanup hooks of the run.
+type Manifest struct {
+	// Path is the temporary file of the manifest.
+	Path string
+	data []byte
+}
+
+// NewManifest returns the manifest with a unique temporary file path.
+func NewManifest(b []byte) (*Manifest, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &Manifest{
+		Path: filepath.Join(os.TempDir(), "kubenet-"+hex.EncodeToString(id)+".yaml"),
+		data: b,
+	}, nil
+}
+
+// Write writes the temporary file, a run.Hook.
+func (r *Manifest) Write(_ context.Context) error {
+	if err := os.WriteFile(r.Path, r.data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Remove removes the temporary file, a run.Hook.
+func (r *Manifest) Remove(_ context.Context) error {
+	if err := os.Remove(r.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
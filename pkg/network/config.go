@@ -0,0 +1,307 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	IPAMAPIVersion = "ipam.be.kuid.dev/v1alpha1"
+	IPIndexKind    = "IPIndex"
+	ConfigKind     = "NetworkConfig"
+
+	// PurposeLabel tells kuid what a prefix of the ip index is used for.
+	PurposeLabel    = "infra.kuid.dev/purpose"
+	PurposeLoopback = "loopback"
+	PurposeLink     = "link-internal"
+
+	// MaxVNI is the largest vxlan network identifier (24 bits).
+	MaxVNI = 1<<24 - 1
+)
+
+// IPIndex is the kuid ip index with the prefixes of the underlay.
+type IPIndex struct {
+	APIVersion string      `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string      `json:"kind" yaml:"kind"`
+	Metadata   Metadata    `json:"metadata" yaml:"metadata"`
+	Spec       IPIndexSpec `json:"spec" yaml:"spec"`
+}
+
+type IPIndexSpec struct {
+	Prefixes []LabeledPrefix `json:"prefixes" yaml:"prefixes"`
+}
+
+type LabeledPrefix struct {
+	Prefix string            `json:"prefix" yaml:"prefix"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// NetworkConfig holds the parameters of the underlay network.
+type NetworkConfig struct {
+	APIVersion string            `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string            `json:"kind" yaml:"kind"`
+	Metadata   Metadata          `json:"metadata" yaml:"metadata"`
+	Spec       NetworkConfigSpec `json:"spec" yaml:"spec"`
+}
+
+type NetworkConfigSpec struct {
+	Topology      string          `json:"topology" yaml:"topology"`
+	Prefixes      []LabeledPrefix `json:"prefixes" yaml:"prefixes"`
+	Protocols     Protocols       `json:"protocols" yaml:"protocols"`
+	Encapsulation Encapsulation   `json:"encapsulation" yaml:"encapsulation"`
+}
+
+type Protocols struct {
+	EBGP    *EBGP     `json:"ebgp,omitempty" yaml:"ebgp,omitempty"`
+	BGPEVPN *struct{} `json:"bgpEVPN,omitempty" yaml:"bgpEVPN,omitempty"`
+}
+
+type EBGP struct {
+	ASPool string `json:"asPool" yaml:"asPool"`
+}
+
+type Encapsulation struct {
+	VXLAN *VXLAN `json:"vxlan,omitempty" yaml:"vxlan,omitempty"`
+}
+
+type VXLAN struct {
+	VNIPool string `json:"vniPool" yaml:"vniPool"`
+}
+
+// Range is an inclusive range of numbers, e.g. an AS or VNI range.
+type Range struct {
+	Start, End uint64
+}
+
+// ParseRange parses a range in the format start-end.
+func ParseRange(s string) (Range, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return Range{}, fmt.Errorf("invalid range %q, expected start-end", s)
+	}
+	var r Range
+	var err error
+	if r.Start, err = strconv.ParseUint(strings.TrimSpace(start), 10, 32); err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if r.End, err = strconv.ParseUint(strings.TrimSpace(end), 10, 32); err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if r.Start > r.End {
+		return Range{}, fmt.Errorf("invalid range %q, start is larger than end", s)
+	}
+	return r, nil
+}
+
+func (r Range) Size() uint64 { return r.End - r.Start + 1 }
+
+func (r Range) String() string { return fmt.Sprintf("%d-%d", r.Start, r.End) }
+
+// UnderlayConfig holds the parameters from which the ip index and network
+// config are generated.
+type UnderlayConfig struct {
+	Topology         string
+	Namespace        string
+	UnderlayPrefixes []string
+	LoopbackPools    []string
+	LinkPools        []string
+	ASRange          string
+	VNIRange         string
+	// Nodes is the number of nodes in the topology, used to check that the
+	// pools are large enough.
+	Nodes int
+}
+
+// Validate checks the prefixes for overlaps and the pools and ranges for
+// sizing against the number of nodes.
+func (r *UnderlayConfig) Validate() error {
+	var errs []string
+
+	parse := func(kind string, ss []string) []netip.Prefix {
+		prefixes := make([]netip.Prefix, 0, len(ss))
+		for _, s := range ss {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("invalid %s prefix %q: %s", kind, s, err))
+				continue
+			}
+			if p != p.Masked() {
+				errs = append(errs, fmt.Sprintf("%s prefix %s has host bits set, did you mean %s?", kind, s, p.Masked()))
+				continue
+			}
+			prefixes = append(prefixes, p)
+		}
+		return prefixes
+	}
+	underlay := parse("underlay", r.UnderlayPrefixes)
+	loopbacks := parse("loopback", r.LoopbackPools)
+	links := parse("link", r.LinkPools)
+
+	if len(underlay) == 0 {
+		errs = append(errs, "at least one underlay prefix is required")
+	}
+	if len(loopbacks) == 0 {
+		errs = append(errs, "at least one loopback pool is required")
+	}
+	if len(links) == 0 {
+		errs = append(errs, "at least one link pool is required")
+	}
+
+	for i := range underlay {
+		for j := i + 1; j < len(underlay); j++ {
+			if underlay[i].Overlaps(underlay[j]) {
+				errs = append(errs, fmt.Sprintf("underlay prefixes %s and %s overlap", underlay[i], underlay[j]))
+			}
+		}
+	}
+	pools := append(append([]netip.Prefix{}, loopbacks...), links...)
+	for i := range pools {
+		if !ContainedIn(pools[i], underlay) {
+			errs = append(errs, fmt.Sprintf("pool %s is not part of an underlay prefix", pools[i]))
+		}
+		for j := i + 1; j < len(pools); j++ {
+			if pools[i].Overlaps(pools[j]) {
+				errs = append(errs, fmt.Sprintf("pools %s and %s overlap", pools[i], pools[j]))
+			}
+		}
+	}
+
+	if r.Nodes > 0 {
+		// every node needs a loopback address per address family
+		for _, af := range []bool{true, false} {
+			if size := poolSize(loopbacks, af, 0); size > 0 && size < uint64(r.Nodes) {
+				errs = append(errs, fmt.Sprintf("loopback pools provide %d %s addresses, %d nodes need one each", size, afName(af), r.Nodes))
+			}
+		}
+		// worst case every node pair is connected with a /31 or /127
+		maxLinks := uint64(r.Nodes * (r.Nodes - 1) / 2)
+		for _, af := range []bool{true, false} {
+			if size := poolSize(links, af, 1); size > 0 && size < maxLinks {
+				errs = append(errs, fmt.Sprintf("link pools provide %d %s point-to-point subnets, a %d node topology can need up to %d", size, afName(af), r.Nodes, maxLinks))
+			}
+		}
+	}
+
+	if as, err := ParseRange(r.ASRange); err != nil {
+		errs = append(errs, "as range: "+err.Error())
+	} else {
+		if as.Start == 0 || as.End > 4294967294 {
+			errs = append(errs, fmt.Sprintf("as range %s must be within 1-4294967294", as))
+		}
+		if r.Nodes > 0 && as.Size() < uint64(r.Nodes) {
+			errs = append(errs, fmt.Sprintf("as range %s provides %d AS numbers, %d nodes need one each", as, as.Size(), r.Nodes))
+		}
+	}
+	if vni, err := ParseRange(r.VNIRange); err != nil {
+		errs = append(errs, "vni range: "+err.Error())
+	} else if vni.Start == 0 || vni.End > MaxVNI {
+		errs = append(errs, fmt.Sprintf("vni range %s must be within 1-%d", vni, MaxVNI))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid network config:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+// ContainedIn returns true when p lies inside one of the parent prefixes.
+func ContainedIn(p netip.Prefix, parents []netip.Prefix) bool {
+	for _, parent := range parents {
+		if parent.Bits() <= p.Bits() && parent.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// poolSize returns the number of subnets of the address family the pools
+// provide, where a subnet has 2^hostBits addresses. The result saturates at
+// 2^32 since larger pools are big enough for any lab.
+func poolSize(pools []netip.Prefix, v4 bool, hostBits int) uint64 {
+	var size uint64
+	for _, p := range pools {
+		if p.Addr().Is4() != v4 {
+			continue
+		}
+		bits := p.Addr().BitLen() - p.Bits() - hostBits
+		if bits >= 32 {
+			return 1 << 32
+		}
+		if bits < 0 {
+			continue
+		}
+		size += 1 << bits
+	}
+	return size
+}
+
+func afName(v4 bool) string {
+	if v4 {
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+// NewUnderlay generates the ip index and network config from the config.
+func NewUnderlay(cfg *UnderlayConfig) (*IPIndex, *NetworkConfig, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	labeled := func(purpose string, ss []string) []LabeledPrefix {
+		prefixes := make([]LabeledPrefix, 0, len(ss))
+		for _, s := range ss {
+			lp := LabeledPrefix{Prefix: s}
+			if purpose != "" {
+				lp.Labels = map[string]string{PurposeLabel: purpose}
+			}
+			prefixes = append(prefixes, lp)
+		}
+		return prefixes
+	}
+
+	idx := &IPIndex{
+		APIVersion: IPAMAPIVersion,
+		Kind:       IPIndexKind,
+		Metadata:   Metadata{Name: "default", Namespace: cfg.Namespace},
+		Spec: IPIndexSpec{
+			Prefixes: labeled("", cfg.UnderlayPrefixes),
+		},
+	}
+
+	nc := &NetworkConfig{
+		APIVersion: APIVersion,
+		Kind:       ConfigKind,
+		Metadata:   Metadata{Name: fmt.Sprintf("%s.%s", cfg.Topology, TypeDefault), Namespace: cfg.Namespace},
+		Spec: NetworkConfigSpec{
+			Topology: cfg.Topology,
+			Prefixes: append(labeled(PurposeLoopback, cfg.LoopbackPools), labeled(PurposeLink, cfg.LinkPools)...),
+			Protocols: Protocols{
+				EBGP:    &EBGP{ASPool: cfg.ASRange},
+				BGPEVPN: &struct{}{},
+			},
+			Encapsulation: Encapsulation{
+				VXLAN: &VXLAN{VNIPool: cfg.VNIRange},
+			},
+		},
+	}
+	return idx, nc, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+func validUnderlay() UnderlayConfig {
+	return UnderlayConfig{
+		Topology:         "kubenet",
+		Namespace:        "default",
+		UnderlayPrefixes: []string{"10.0.0.0/16", "1000::/32"},
+		LoopbackPools:    []string{"10.0.0.0/24", "1000::/64"},
+		LinkPools:        []string{"10.0.1.0/24", "1000:0:0:1::/64"},
+		ASRange:          "65000-65100",
+		VNIRange:         "200000-300000",
+		Nodes:            4,
+	}
+}
+
+func TestUnderlayConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		modify func(*UnderlayConfig)
+		errs   []string
+	}{
+		"valid": {
+			modify: func(*UnderlayConfig) {},
+		},
+		"invalid prefix": {
+			modify: func(c *UnderlayConfig) { c.LoopbackPools = []string{"10.0.0.0/33"} },
+			errs:   []string{`invalid loopback prefix "10.0.0.0/33"`},
+		},
+		"host bits set": {
+			modify: func(c *UnderlayConfig) { c.LinkPools = []string{"10.0.1.1/24"} },
+			errs:   []string{"did you mean 10.0.1.0/24?"},
+		},
+		"missing pools": {
+			modify: func(c *UnderlayConfig) { c.UnderlayPrefixes, c.LoopbackPools, c.LinkPools = nil, nil, nil },
+			errs: []string{
+				"at least one underlay prefix is required",
+				"at least one loopback pool is required",
+				"at least one link pool is required",
+			},
+		},
+		"overlapping underlay prefixes": {
+			modify: func(c *UnderlayConfig) { c.UnderlayPrefixes = append(c.UnderlayPrefixes, "10.0.128.0/17") },
+			errs:   []string{"underlay prefixes 10.0.0.0/16 and 10.0.128.0/17 overlap"},
+		},
+		"overlapping pools": {
+			modify: func(c *UnderlayConfig) { c.LinkPools = []string{"10.0.0.128/25"} },
+			errs:   []string{"pools 10.0.0.0/24 and 10.0.0.128/25 overlap"},
+		},
+		"pool outside underlay": {
+			modify: func(c *UnderlayConfig) { c.LinkPools = []string{"192.168.0.0/24"} },
+			errs:   []string{"pool 192.168.0.0/24 is not part of an underlay prefix"},
+		},
+		"loopback pool too small": {
+			modify: func(c *UnderlayConfig) { c.LoopbackPools = []string{"10.0.0.0/31"} },
+			errs:   []string{"loopback pools provide 2 ipv4 addresses, 4 nodes need one each"},
+		},
+		"link pool too small": {
+			modify: func(c *UnderlayConfig) { c.LinkPools = []string{"10.0.1.0/30"} },
+			errs:   []string{"link pools provide 2 ipv4 point-to-point subnets, a 4 node topology can need up to 6"},
+		},
+		"sizing skipped without nodes": {
+			modify: func(c *UnderlayConfig) { c.LoopbackPools, c.Nodes = []string{"10.0.0.0/31"}, 0 },
+		},
+		"as range too small": {
+			modify: func(c *UnderlayConfig) { c.ASRange = "65000-65001" },
+			errs:   []string{"as range 65000-65001 provides 2 AS numbers, 4 nodes need one each"},
+		},
+		"as range starts at zero": {
+			modify: func(c *UnderlayConfig) { c.ASRange = "0-100" },
+			errs:   []string{"as range 0-100 must be within 1-4294967294"},
+		},
+		"invalid as range": {
+			modify: func(c *UnderlayConfig) { c.ASRange = "65100-65000" },
+			errs:   []string{"as range: invalid range \"65100-65000\", start is larger than end"},
+		},
+		"vni range too large": {
+			modify: func(c *UnderlayConfig) { c.VNIRange = "1-16777216" },
+			errs:   []string{"vni range 1-16777216 must be within 1-16777215"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := validUnderlay()
+			tc.modify(&cfg)
+			err := cfg.Validate()
+			if len(tc.errs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tc.errs)
+			}
+			for _, want := range tc.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    Range
+		wantErr bool
+	}{
+		"range":        {in: "65000-65100", want: Range{65000, 65100}},
+		"spaces":       {in: " 1 - 2 ", want: Range{1, 2}},
+		"single":       {in: "5-5", want: Range{5, 5}},
+		"no separator": {in: "65000", wantErr: true},
+		"not a number": {in: "a-2", wantErr: true},
+		"reversed":     {in: "2-1", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseRange(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseRange(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseRange(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
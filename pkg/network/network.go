@@ -0,0 +1,289 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	APIVersion = "network.app.kuid.dev/v1alpha1"
+	Kind       = "Network"
+
+	DefaultRegion    = "region1"
+	DefaultSite      = "site1"
+	DefaultNamespace = "default"
+)
+
+// Type is the kind of overlay network that is generated.
+type Type string
+
+const (
+	TypeBridged Type = "bridged"
+	TypeRouted  Type = "routed"
+	TypeIRB     Type = "irb"
+	// TypeDefault is the underlay network created by `kubenet networkdefault`.
+	TypeDefault Type = "default"
+)
+
+// Types lists the supported network types.
+var Types = []Type{TypeBridged, TypeRouted, TypeIRB}
+
+func ParseType(s string) (Type, error) {
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported network type %q, expected one of %v", s, Types)
+}
+
+// Network is the kuid Network custom resource.
+type Network struct {
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Metadata   Metadata `json:"metadata" yaml:"metadata"`
+	Spec       Spec     `json:"spec" yaml:"spec"`
+	Status     *Status  `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+type Metadata struct {
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	UID       string `json:"uid,omitempty" yaml:"-"`
+}
+
+type Spec struct {
+	Topology      string         `json:"topology" yaml:"topology"`
+	BridgeDomains []BridgeDomain `json:"bridgeDomains,omitempty" yaml:"bridgeDomains,omitempty"`
+	RoutingTables []RoutingTable `json:"routingTables,omitempty" yaml:"routingTables,omitempty"`
+}
+
+type BridgeDomain struct {
+	Name       string      `json:"name" yaml:"name"`
+	NetworkID  int         `json:"networkID,omitempty" yaml:"networkID,omitempty"`
+	Interfaces []Interface `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+}
+
+type RoutingTable struct {
+	Name       string      `json:"name" yaml:"name"`
+	NetworkID  int         `json:"networkID,omitempty" yaml:"networkID,omitempty"`
+	Prefixes   []Prefix    `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	Interfaces []Interface `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+}
+
+type Prefix struct {
+	Prefix string `json:"prefix" yaml:"prefix"`
+}
+
+// Interface attaches a bridge domain or routing table to an endpoint of a
+// node. In a routing table an interface can also refer to a bridge domain,
+// which results in an IRB interface.
+type Interface struct {
+	BridgeDomain string `json:"bridgeDomain,omitempty" yaml:"bridgeDomain,omitempty"`
+	Region       string `json:"region,omitempty" yaml:"region,omitempty"`
+	Site         string `json:"site,omitempty" yaml:"site,omitempty"`
+	Node         string `json:"node,omitempty" yaml:"node,omitempty"`
+	Endpoint     string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	VLANID       int    `json:"vlanID,omitempty" yaml:"vlanID,omitempty"`
+}
+
+// Attachment is an endpoint on a node the network is attached to.
+type Attachment struct {
+	Node     string
+	Endpoint string
+	VLANID   int
+}
+
+// ParseAttachment parses an attachment in the format node:endpoint[:vlan].
+func ParseAttachment(s string, defaultVLAN int) (Attachment, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Attachment{}, fmt.Errorf("invalid interface %q, expected node:endpoint[:vlan]", s)
+	}
+	a := Attachment{Node: parts[0], Endpoint: parts[1], VLANID: defaultVLAN}
+	if len(parts) == 3 {
+		vlan, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return Attachment{}, fmt.Errorf("invalid vlan in interface %q: %w", s, err)
+		}
+		a.VLANID = vlan
+	}
+	return a, nil
+}
+
+func (a Attachment) String() string {
+	return fmt.Sprintf("%s:%s:%d", a.Node, a.Endpoint, a.VLANID)
+}
+
+// Config holds the parameters from which a network is generated.
+type Config struct {
+	Name        string
+	Namespace   string
+	Type        Type
+	Topology    string
+	Region      string
+	Site        string
+	NetworkID   int
+	Attachments []Attachment
+	Prefixes    []string
+}
+
+// New generates a Network resource from the config.
+func New(cfg *Config) (*Network, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	n := &Network{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Metadata: Metadata{
+			Name:      fmt.Sprintf("%s.%s", cfg.Topology, cfg.Name),
+			Namespace: cfg.Namespace,
+		},
+		Spec: Spec{
+			Topology: cfg.Topology,
+		},
+	}
+
+	bdName := fmt.Sprintf("%s.br%d", cfg.Name, cfg.NetworkID)
+	rtName := fmt.Sprintf("%s.rt", cfg.Name)
+
+	switch cfg.Type {
+	case TypeBridged:
+		n.Spec.BridgeDomains = []BridgeDomain{{
+			Name:       bdName,
+			NetworkID:  cfg.NetworkID,
+			Interfaces: cfg.interfaces(),
+		}}
+	case TypeRouted:
+		n.Spec.RoutingTables = []RoutingTable{{
+			Name:       rtName,
+			NetworkID:  cfg.NetworkID,
+			Prefixes:   cfg.prefixes(),
+			Interfaces: cfg.interfaces(),
+		}}
+	case TypeIRB:
+		n.Spec.BridgeDomains = []BridgeDomain{{
+			Name:       bdName,
+			NetworkID:  cfg.NetworkID,
+			Interfaces: cfg.interfaces(),
+		}}
+		n.Spec.RoutingTables = []RoutingTable{{
+			Name:       rtName,
+			NetworkID:  cfg.NetworkID,
+			Prefixes:   cfg.prefixes(),
+			Interfaces: []Interface{{BridgeDomain: bdName}},
+		}}
+	}
+	return n, nil
+}
+
+// Validate checks the config for errors that do not require the cluster.
+func (r *Config) Validate() error {
+	var errs []string
+	if r.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	if _, err := ParseType(string(r.Type)); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if r.Topology == "" {
+		errs = append(errs, "topology is required")
+	}
+	if r.NetworkID <= 0 {
+		errs = append(errs, fmt.Sprintf("network id must be positive, got %d", r.NetworkID))
+	}
+	if len(r.Attachments) == 0 {
+		errs = append(errs, "at least one interface is required")
+	}
+	seen := map[string]bool{}
+	for _, a := range r.Attachments {
+		if a.VLANID < 1 || a.VLANID > 4094 {
+			errs = append(errs, fmt.Sprintf("interface %s: vlan must be between 1 and 4094", a))
+		}
+		key := a.Node + ":" + a.Endpoint
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("interface %s:%s is specified more than once", a.Node, a.Endpoint))
+		}
+		seen[key] = true
+	}
+	if r.Type == TypeRouted || r.Type == TypeIRB {
+		if len(r.Prefixes) == 0 {
+			errs = append(errs, fmt.Sprintf("a %s network requires at least one prefix", r.Type))
+		}
+	}
+	for _, p := range r.Prefixes {
+		if _, err := netip.ParsePrefix(p); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid prefix %q: %s", p, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid network %q: %s", r.Name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (r *Config) interfaces() []Interface {
+	itfces := make([]Interface, 0, len(r.Attachments))
+	for _, a := range r.Attachments {
+		itfces = append(itfces, Interface{
+			Region:   r.Region,
+			Site:     r.Site,
+			Node:     a.Node,
+			Endpoint: a.Endpoint,
+			VLANID:   a.VLANID,
+		})
+	}
+	return itfces
+}
+
+func (r *Config) prefixes() []Prefix {
+	prefixes := make([]Prefix, 0, len(r.Prefixes))
+	for _, p := range r.Prefixes {
+		prefixes = append(prefixes, Prefix{Prefix: p})
+	}
+	return prefixes
+}
+
+// YAML renders the network as a yaml manifest.
+func (r *Network) YAML() ([]byte, error) {
+	return Marshal(r)
+}
+
+// Marshal renders the objects as a multi document yaml manifest.
+func Marshal(objs ...any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, obj := range objs {
+		if err := enc.Encode(obj); err != nil {
+			return nil, fmt.Errorf("cannot render manifest: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("cannot render manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
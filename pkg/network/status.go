@@ -0,0 +1,222 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+)
+
+const (
+	// Resource is the kubectl resource name of the network custom resource.
+	Resource = "networks.network.app.kuid.dev"
+	// DeviceResource is the per device network config derived by kuid.
+	DeviceResource = "networkdevices.network.app.kuid.dev"
+)
+
+// claimResources are the kuid claims a network allocates its resources with.
+var claimResources = []string{
+	"genidclaims.genid.be.kuid.dev",
+	"vlanclaims.vlan.be.kuid.dev",
+	"ipclaims.ipam.be.kuid.dev",
+}
+
+type Status struct {
+	Conditions []Condition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+type Condition struct {
+	Type               string `json:"type" yaml:"type"`
+	Status             string `json:"status" yaml:"status"`
+	Reason             string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Message            string `json:"message,omitempty" yaml:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
+}
+
+// Type derives the network type from the spec.
+func (r *Network) Type() Type {
+	switch {
+	case strings.HasSuffix(r.Metadata.Name, "."+string(TypeDefault)):
+		return TypeDefault
+	case len(r.Spec.BridgeDomains) > 0 && len(r.Spec.RoutingTables) > 0:
+		return TypeIRB
+	case len(r.Spec.RoutingTables) > 0:
+		return TypeRouted
+	default:
+		return TypeBridged
+	}
+}
+
+// Ready returns the status of the Ready condition.
+func (r *Network) Ready() string {
+	return conditionStatus(r.Status, "Ready")
+}
+
+func conditionStatus(s *Status, t string) string {
+	if s == nil {
+		return "Unknown"
+	}
+	for _, c := range s.Conditions {
+		if c.Type == t {
+			return c.Status
+		}
+	}
+	return "Unknown"
+}
+
+// List returns the networks in the namespace, or in all namespaces when the
+// namespace is empty.
+func List(ctx context.Context, namespace string) ([]Network, error) {
+	l := struct {
+		Items []Network `json:"items"`
+	}{}
+	if err := kubectl.Get(ctx, &l, Resource, namespaceArg(namespace)); err != nil {
+		return nil, err
+	}
+	sort.Slice(l.Items, func(i, j int) bool {
+		if l.Items[i].Metadata.Namespace != l.Items[j].Metadata.Namespace {
+			return l.Items[i].Metadata.Namespace < l.Items[j].Metadata.Namespace
+		}
+		return l.Items[i].Metadata.Name < l.Items[j].Metadata.Name
+	})
+	return l.Items, nil
+}
+
+// Get returns a network. The topology prefix of the name can be omitted.
+func Get(ctx context.Context, namespace, name string) (*Network, error) {
+	nets, err := List(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nets {
+		if nets[i].Metadata.Name == name || nets[i].Metadata.Name == nets[i].Spec.Topology+"."+name {
+			return &nets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("network %s not found in namespace %s", name, namespace)
+}
+
+// Claim is a resource kuid allocated for a network, e.g. a vni, vlan or
+// prefix.
+type Claim struct {
+	Kind  string
+	Name  string
+	Value string
+}
+
+// Device is a device the network is deployed on.
+type Device struct {
+	Name  string
+	Ready string
+}
+
+type ownedObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name            string `json:"name"`
+		OwnerReferences []struct {
+			UID string `json:"uid"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Status map[string]any `json:"status"`
+}
+
+func (r *ownedObject) ownedBy(uid string) bool {
+	for _, ref := range r.Metadata.OwnerReferences {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func getOwned(ctx context.Context, resource string, n *Network) ([]ownedObject, error) {
+	l := struct {
+		Items []ownedObject `json:"items"`
+	}{}
+	if err := kubectl.Get(ctx, &l, resource, namespaceArg(n.Metadata.Namespace)); err != nil {
+		return nil, err
+	}
+	owned := []ownedObject{}
+	for _, o := range l.Items {
+		if o.ownedBy(n.Metadata.UID) {
+			owned = append(owned, o)
+		}
+	}
+	return owned, nil
+}
+
+// GetClaims returns the vnis, vlans and prefixes kuid resolved for the
+// network.
+func GetClaims(ctx context.Context, n *Network) ([]Claim, error) {
+	claims := []Claim{}
+	for _, resource := range claimResources {
+		objs, err := getOwned(ctx, resource, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			claims = append(claims, Claim{
+				Kind:  o.Kind,
+				Name:  o.Metadata.Name,
+				Value: claimValue(o.Status),
+			})
+		}
+	}
+	return claims, nil
+}
+
+func claimValue(status map[string]any) string {
+	for _, key := range []string{"id", "prefix", "address", "range"} {
+		if v, ok := status[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return "<pending>"
+}
+
+// GetDevices returns the devices the network touched.
+func GetDevices(ctx context.Context, n *Network) ([]Device, error) {
+	objs, err := getOwned(ctx, DeviceResource, n)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(objs))
+	for _, o := range objs {
+		d := Device{Name: o.Metadata.Name, Ready: "Unknown"}
+		if conds, ok := o.Status["conditions"].([]any); ok {
+			for _, c := range conds {
+				if m, ok := c.(map[string]any); ok && m["type"] == "Ready" {
+					d.Ready = fmt.Sprint(m["status"])
+				}
+			}
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+func namespaceArg(namespace string) string {
+	if namespace == "" {
+		return "--all-namespaces"
+	}
+	return "--namespace=" + namespace
+}
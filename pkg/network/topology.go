@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/kubectl"
+)
+
+// Topology holds the nodes and their endpoints of a topology.
+type Topology struct {
+	Name  string
+	Nodes map[string]map[string]bool
+}
+
+type endpointList struct {
+	Items []struct {
+		Spec struct {
+			Topology string `json:"topology"`
+			Node     string `json:"node"`
+			Endpoint string `json:"endpoint"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+// GetTopology retrieves the endpoints of the topology from the kuid
+// inventory in the cluster.
+func GetTopology(ctx context.Context, name string) (*Topology, error) {
+	eps := endpointList{}
+	if err := kubectl.Get(ctx, &eps, "endpoints.infra.kuid.dev", "--all-namespaces"); err != nil {
+		return nil, fmt.Errorf("cannot get topology %s: %w", name, err)
+	}
+	t := &Topology{Name: name, Nodes: map[string]map[string]bool{}}
+	for _, ep := range eps.Items {
+		if ep.Spec.Topology != name {
+			continue
+		}
+		t.AddEndpoint(ep.Spec.Node, ep.Spec.Endpoint)
+	}
+	if len(t.Nodes) == 0 {
+		return nil, exitcode.Preconditionf("topology %s not found in the cluster, did you run `kubenet inventory`?", name)
+	}
+	return t, nil
+}
+
+func (r *Topology) AddEndpoint(node, endpoint string) {
+	if _, ok := r.Nodes[node]; !ok {
+		r.Nodes[node] = map[string]bool{}
+	}
+	r.Nodes[node][endpoint] = true
+}
+
+// NodeNames returns the sorted node names of the topology.
+func (r *Topology) NodeNames() []string {
+	nodes := make([]string, 0, len(r.Nodes))
+	for node := range r.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Validate checks that every interface of the network refers to a node and
+// endpoint that exists in the topology.
+func (r *Topology) Validate(n *Network) error {
+	var errs []string
+	if n.Spec.Topology != r.Name {
+		errs = append(errs, fmt.Sprintf("network refers to topology %s, expected %s", n.Spec.Topology, r.Name))
+	}
+	check := func(itfce Interface) {
+		if itfce.Node == "" {
+			return
+		}
+		eps, ok := r.Nodes[itfce.Node]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("node %s does not exist in topology %s", itfce.Node, r.Name))
+			return
+		}
+		if !eps[itfce.Endpoint] {
+			errs = append(errs, fmt.Sprintf("endpoint %s does not exist on node %s", itfce.Endpoint, itfce.Node))
+		}
+	}
+	for _, bd := range n.Spec.BridgeDomains {
+		for _, itfce := range bd.Interfaces {
+			check(itfce)
+		}
+	}
+	for _, rt := range n.Spec.RoutingTables {
+		for _, itfce := range rt.Interfaces {
+			check(itfce)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("network %s does not match the topology: %s", n.Metadata.Name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ListTopologies returns the names of the topologies in the kuid inventory.
+func ListTopologies(ctx context.Context) ([]string, error) {
+	eps := endpointList{}
+	if err := kubectl.Get(ctx, &eps, "endpoints.infra.kuid.dev", "--all-namespaces"); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	names := []string{}
+	for _, ep := range eps.Items {
+		if !seen[ep.Spec.Topology] {
+			seen[ep.Spec.Topology] = true
+			names = append(names, ep.Spec.Topology)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/config"
+)
+
+// FileName is the name of the progress file in the state directory of a
+// profile.
+const FileName = "progress.json"
+
+// File returns the progress file of the current profile.
+func File() string {
+	return filepath.Join(config.CurrentProfile().StateDir(), FileName)
+}
+
+// Exercise is the progress of a student on an exercise.
+type Exercise struct {
+	Started        time.Time  `json:"started"`
+	HintsRevealed  int        `json:"hintsRevealed,omitempty"`
+	SolutionViewed bool       `json:"solutionViewed,omitempty"`
+	Attempts       int        `json:"attempts,omitempty"`
+	Completed      *time.Time `json:"completed,omitempty"`
+}
+
+// Progress is the progress on all exercises, stored in a file.
+type Progress struct {
+	path      string
+	Exercises map[string]*Exercise `json:"exercises"`
+}
+
+// Load reads the progress file, a missing file has no progress.
+func Load(path string) (*Progress, error) {
+	p := &Progress{path: path, Exercises: map[string]*Exercise{}}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("invalid progress file %s: %w", path, err)
+	}
+	if p.Exercises == nil {
+		p.Exercises = map[string]*Exercise{}
+	}
+	return p, nil
+}
+
+// Get returns the progress on the exercise, it is started when it is not
+// known yet.
+func (r *Progress) Get(name string) *Exercise {
+	e, ok := r.Exercises[name]
+	if !ok {
+		e = &Exercise{Started: time.Now().UTC()}
+		r.Exercises[name] = e
+	}
+	return e
+}
+
+// Status returns the status of the exercise: not started, in progress or
+// completed.
+func (r *Progress) Status(name string) string {
+	e, ok := r.Exercises[name]
+	switch {
+	case !ok:
+		return "not started"
+	case e.Completed != nil:
+		return "completed"
+	default:
+		return "in progress"
+	}
+}
+
+// Complete marks the exercise completed.
+func (r *Exercise) Complete() {
+	if r.Completed == nil {
+		now := time.Now().UTC()
+		r.Completed = &now
+	}
+}
+
+// Reset clears the completion of all exercises, e.g. when the cluster they
+// were done on is destroyed. Hints and attempts are kept.
+func (r *Progress) Reset() {
+	for _, e := range r.Exercises {
+		e.Completed = nil
+	}
+}
+
+// Save writes the progress file.
+func (r *Progress) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, b, 0600)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Prompter asks questions on out and reads the answers from in.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// IsTerminal returns true when f is attached to a terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// String asks a question and returns the answer, or def when the answer is
+// empty.
+func (r *Prompter) String(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(r.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(r.out, "%s: ", question)
+	}
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("unable to read answer: %w", err)
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// Password asks for a secret on the terminal f without echoing it.
+func Password(f *os.File, out io.Writer, question string) (string, error) {
+	if !IsTerminal(f) {
+		return "", fmt.Errorf("unable to ask %s: not a terminal", question)
+	}
+	fmt.Fprintf(out, "%s: ", question)
+	b, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", fmt.Errorf("unable to read answer: %w", err)
+	}
+	return string(b), nil
+}
+
+// Int asks a question that expects a number.
+func (r *Prompter) Int(question string, def int) (int, error) {
+	for {
+		s, err := r.String(question, strconv.Itoa(def))
+		if err != nil {
+			return 0, err
+		}
+		i, err := strconv.Atoi(s)
+		if err == nil {
+			return i, nil
+		}
+		fmt.Fprintf(r.out, "%q is not a number\n", s)
+	}
+}
+
+// List asks a question that expects a comma separated list.
+func (r *Prompter) List(question string, def []string) ([]string, error) {
+	s, err := r.String(question, strings.Join(def, ","))
+	if err != nil {
+		return nil, err
+	}
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Confirm asks a yes/no question.
+func (r *Prompter) Confirm(question string, def bool) (bool, error) {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	s, err := r.String(question+" ("+d+")", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(s) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
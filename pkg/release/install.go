@@ -0,0 +1,110 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Extract returns the binary from a tar.gz release archive.
+func Extract(archive []byte, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive does not contain %s", binary)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == binary {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// Executable returns the path of the running binary, with symlinks
+// resolved.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Replace atomically replaces the binary at target. The new binary is
+// written next to the target and renamed over it; when it does not run the
+// previous binary is restored.
+func Replace(ctx context.Context, target string, binary []byte) error {
+	dir := filepath.Dir(target)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(target)+".new-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("cannot write to %s, rerun with sudo: %w", dir, err)
+		}
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err := f.Write(binary); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	backup := target + ".old"
+	if err := os.Rename(target, backup); err != nil {
+		return fmt.Errorf("cannot back up %s: %w", target, err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return rollback(backup, target, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, target, "version").CombinedOutput(); err != nil {
+		return rollback(backup, target, fmt.Errorf("new binary does not run: %w: %s", err, bytes.TrimSpace(out)))
+	}
+	return os.Remove(backup)
+}
+
+func rollback(backup, target string, cause error) error {
+	if err := os.Rename(backup, target); err != nil {
+		return fmt.Errorf("%w; restoring %s from %s failed: %s", cause, target, backup, err)
+	}
+	return fmt.Errorf("%w; restored the previous binary", cause)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	tests := map[string]struct {
+		archive []byte
+		want    string
+		wantErr string
+	}{
+		"binary": {
+			archive: archive(t, map[string]string{"LICENSE": "license", "kubenet": "binary"}),
+			want:    "binary",
+		},
+		"nested binary": {
+			archive: archive(t, map[string]string{"kubenet_1.0.0/kubenet": "nested"}),
+			want:    "nested",
+		},
+		"missing binary": {
+			archive: archive(t, map[string]string{"README.md": "readme"}),
+			wantErr: "archive does not contain kubenet",
+		},
+		"not gzip": {
+			archive: []byte("not an archive"),
+			wantErr: "cannot read archive",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Extract(tc.archive, "kubenet")
+			checkErr(t, err, tc.wantErr)
+			if string(got) != tc.want {
+				t.Errorf("Extract() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	const previous = "#!/bin/sh\necho previous\n"
+	tests := map[string]struct {
+		binary  string
+		wantErr string
+		want    string
+	}{
+		"replaced": {
+			binary: "#!/bin/sh\necho new\n",
+			want:   "#!/bin/sh\necho new\n",
+		},
+		"rolled back when the new binary fails": {
+			binary:  "#!/bin/sh\necho broken >&2\nexit 1\n",
+			wantErr: "restored the previous binary",
+			want:    previous,
+		},
+		"rolled back when the new binary is not executable": {
+			binary:  "not a binary",
+			wantErr: "new binary does not run",
+			want:    previous,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "kubenet")
+			if err := os.WriteFile(target, []byte(previous), 0755); err != nil {
+				t.Fatal(err)
+			}
+			err := Replace(context.Background(), target, []byte(tc.binary))
+			checkErr(t, err, tc.wantErr)
+
+			got, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("target = %q, want %q", got, tc.want)
+			}
+			// neither the backup nor the temporary file are left behind
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected only the target in %s, got %d entries", dir, len(entries))
+			}
+		})
+	}
+}
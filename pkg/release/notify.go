@@ -0,0 +1,98 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// CheckInterval is how long the latest release is cached.
+	CheckInterval = 24 * time.Hour
+	// CheckTimeout bounds the query of the latest release.
+	CheckTimeout = 2 * time.Second
+)
+
+type checkCache struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// Checker looks up the latest release at most once per interval and caches
+// the result in a file.
+type Checker struct {
+	Source    Source
+	CacheFile string
+	Interval  time.Duration
+	Timeout   time.Duration
+	now       func() time.Time
+}
+
+func NewChecker(src Source, cacheFile string) *Checker {
+	return &Checker{
+		Source:    src,
+		CacheFile: cacheFile,
+		Interval:  CheckInterval,
+		Timeout:   CheckTimeout,
+		now:       time.Now,
+	}
+}
+
+// Latest returns the tag of the latest release, from the cache when it was
+// checked within the interval. It returns "" when the release cannot be
+// determined, e.g. offline.
+func (r *Checker) Latest(ctx context.Context) string {
+	cache := checkCache{}
+	if b, err := os.ReadFile(r.CacheFile); err == nil {
+		if json.Unmarshal(b, &cache) == nil && r.now().Sub(cache.CheckedAt) < r.Interval {
+			return cache.Latest
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	cache = checkCache{CheckedAt: r.now()}
+	if rel, err := r.Source.Latest(ctx); err == nil {
+		cache.Latest = rel.Tag
+	}
+	// a failed check is cached as well so an offline host is not slowed down
+	// by every command
+	if b, err := json.Marshal(cache); err == nil {
+		if os.MkdirAll(filepath.Dir(r.CacheFile), 0700) == nil {
+			_ = os.WriteFile(r.CacheFile, b, 0600)
+		}
+	}
+	return cache.Latest
+}
+
+// Start looks up the latest release in the background. The channel returns
+// the newer release or "" when current is up to date.
+func (r *Checker) Start(ctx context.Context, current string) <-chan string {
+	ch := make(chan string, 1)
+	go func() {
+		latest := r.Latest(ctx)
+		if latest != "" && Compare(latest, current) > 0 {
+			ch <- latest
+		}
+		close(ch)
+	}()
+	return ch
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// releaseServer is a local stand-in of the GitHub releases API that counts
+// the queries of the latest release.
+func releaseServer(t *testing.T, tag string, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	queries := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		queries.Add(1)
+		if req.URL.Path != "/repos/"+Repo+"/releases/latest" {
+			http.NotFound(w, req)
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return
+		}
+		fmt.Fprintf(w, `{"tag_name": %q}`, tag)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, queries
+}
+
+func TestCheckerCache(t *testing.T) {
+	srv, queries := releaseServer(t, "v1.2.0", 0)
+	now := time.Now()
+	c := NewChecker(NewGitHub(srv.URL), filepath.Join(t.TempDir(), "update-check.json"))
+	c.now = func() time.Time { return now }
+
+	if got := c.Latest(context.Background()); got != "v1.2.0" {
+		t.Fatalf("Latest() = %q, want v1.2.0", got)
+	}
+	now = now.Add(CheckInterval - time.Minute)
+	if got := c.Latest(context.Background()); got != "v1.2.0" {
+		t.Fatalf("cached Latest() = %q, want v1.2.0", got)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Fatalf("the release was queried %d times within the interval, want 1", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	c.Latest(context.Background())
+	if n := queries.Load(); n != 2 {
+		t.Fatalf("the release was queried %d times after the interval, want 2", n)
+	}
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	if CheckTimeout != 2*time.Second {
+		t.Errorf("CheckTimeout = %s, want 2s", CheckTimeout)
+	}
+	if c := NewChecker(nil, ""); c.Timeout != CheckTimeout {
+		t.Errorf("NewChecker().Timeout = %s, want %s", c.Timeout, CheckTimeout)
+	}
+
+	srv, queries := releaseServer(t, "v1.2.0", time.Minute)
+	c := NewChecker(NewGitHub(srv.URL), filepath.Join(t.TempDir(), "update-check.json"))
+	c.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if got := c.Latest(context.Background()); got != "" {
+		t.Fatalf("Latest() = %q of a slow source, want none", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Latest() took %s, the timeout is %s", elapsed, c.Timeout)
+	}
+	// the failed check is cached, the next command does not wait again
+	c.Latest(context.Background())
+	if n := queries.Load(); n != 1 {
+		t.Fatalf("the release was queried %d times, want 1", n)
+	}
+}
+
+func TestCheckerOffline(t *testing.T) {
+	srv, _ := releaseServer(t, "v1.2.0", 0)
+	srv.Close()
+	c := NewChecker(NewGitHub(srv.URL), filepath.Join(t.TempDir(), "update-check.json"))
+
+	select {
+	case latest, ok := <-c.Start(context.Background(), "v1.0.0"):
+		if ok {
+			t.Fatalf("Start() offline returned %q, want no release", latest)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() offline did not finish")
+	}
+}
+
+func TestCheckerStart(t *testing.T) {
+	tests := map[string]struct {
+		current string
+		want    string
+	}{
+		"newer release":     {current: "v1.1.0", want: "v1.2.0"},
+		"up to date":        {current: "v1.2.0"},
+		"development build": {current: "v1.3.0-dev"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, _ := releaseServer(t, "v1.2.0", 0)
+			c := NewChecker(NewGitHub(srv.URL), filepath.Join(t.TempDir(), "update-check.json"))
+			if got := <-c.Start(context.Background(), tc.current); got != tc.want {
+				t.Errorf("Start(%q) = %q, want %q", tc.current, got, tc.want)
+			}
+		})
+	}
+}
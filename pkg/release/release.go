@@ -0,0 +1,229 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+const (
+	Project = "kubenetctl"
+	Repo    = "kubenet-dev/kubenetctl"
+
+	// DefaultAPIURL is the GitHub API the releases are queried from.
+	DefaultAPIURL = "https://api.github.com"
+)
+
+// Release is a published version of kubenetctl.
+type Release struct {
+	Tag    string
+	URL    string
+	Assets map[string]string
+}
+
+// Source lists the published releases.
+type Source interface {
+	// Latest returns the most recent release.
+	Latest(ctx context.Context) (*Release, error)
+	// Get returns the release with the tag.
+	Get(ctx context.Context, tag string) (*Release, error)
+}
+
+// GitHub is a Source backed by the GitHub releases API or a server that
+// mimics it.
+type GitHub struct {
+	APIURL string
+	Repo   string
+	Token  string
+	Client *http.Client
+}
+
+// NewGitHub returns the source of the kubenetctl releases. An empty apiURL
+// uses the GitHub API.
+func NewGitHub(apiURL string) *GitHub {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &GitHub{
+		APIURL: strings.TrimSuffix(apiURL, "/"),
+		Repo:   Repo,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type ghRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (r *GitHub) Latest(ctx context.Context) (*Release, error) {
+	return r.get(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", r.APIURL, r.Repo))
+}
+
+func (r *GitHub) Get(ctx context.Context, tag string) (*Release, error) {
+	return r.get(ctx, fmt.Sprintf("%s/repos/%s/releases/tags/%s", r.APIURL, r.Repo, Tag(tag)))
+}
+
+func (r *GitHub) get(ctx context.Context, url string) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, downloadError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("release not found: %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &exitcode.DownloadError{URL: url, Err: fmt.Errorf("server returned %s", resp.Status)}
+	}
+	gr := ghRelease{}
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return nil, fmt.Errorf("cannot decode release: %w", err)
+	}
+	rel := &Release{Tag: gr.TagName, URL: gr.HTMLURL, Assets: map[string]string{}}
+	for _, a := range gr.Assets {
+		rel.Assets[a.Name] = a.URL
+	}
+	return rel, nil
+}
+
+// Download fetches the asset of the release.
+func Download(ctx context.Context, client *http.Client, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return downloadError(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &exitcode.DownloadError{URL: url, Err: fmt.Errorf("server returned %s", resp.Status)}
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return downloadError(url, err)
+	}
+	return nil
+}
+
+// downloadError returns a TimeoutError when the download did not complete
+// in time, a DownloadError otherwise.
+func downloadError(url string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		// the error tells whether the client or the context timed out
+		return &exitcode.TimeoutError{Op: "download of " + url, Err: err}
+	}
+	return &exitcode.DownloadError{URL: url, Err: err}
+}
+
+// ArchiveName returns the name of the release archive for the platform, as
+// produced by goreleaser.
+func ArchiveName(tag, goos, goarch string) string {
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	case "arm":
+		arch = "armv7"
+	case "arm64":
+		arch = "aarch64"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.tar.gz", Project, strings.TrimPrefix(tag, "v"), goos, arch)
+}
+
+// Archive returns the name and url of the archive for this platform.
+func (r *Release) Archive() (string, string, error) {
+	name := ArchiveName(r.Tag, runtime.GOOS, runtime.GOARCH)
+	url, ok := r.Assets[name]
+	if !ok {
+		return "", "", fmt.Errorf("release %s has no archive for %s/%s", r.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	return name, url, nil
+}
+
+// Checksums returns the name and url of the checksums file.
+func (r *Release) Checksums() (string, string, error) {
+	for _, name := range []string{Project + "_checksums.txt", "checksums.txt"} {
+		if url, ok := r.Assets[name]; ok {
+			return name, url, nil
+		}
+	}
+	return "", "", fmt.Errorf("release %s has no checksums file", r.Tag)
+}
+
+// Tag returns the version with a v prefix.
+func Tag(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// Compare compares two versions like v1.2.3, ignoring pre-release and
+// build suffixes. It returns -1, 0 or 1.
+func Compare(a, b string) int {
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < 3; i++ {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func parts(version string) [3]int {
+	p := [3]int{}
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	for i, s := range strings.SplitN(version, ".", 3) {
+		p[i], _ = strconv.Atoi(s)
+	}
+	return p
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// VerifySHA256 checks the digest of data against the entry of name in a
+// checksums file in the `sha256sum` format.
+func VerifySHA256(checksums []byte, name string, data []byte) error {
+	want := ""
+	s := bufio.NewScanner(bytes.NewReader(checksums))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			want = strings.ToLower(fields[0])
+			break
+		}
+	}
+	if want == "" {
+		return fmt.Errorf("no checksum for %s", name)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, expected %s", name, got, want)
+	}
+	return nil
+}
+
+// PublicKey is a minisign public key.
+type PublicKey struct {
+	KeyID [8]byte
+	Key   ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign public key, either the base64 key itself
+// or the path of a .pub file.
+func ParsePublicKey(s string) (*PublicKey, error) {
+	if b, err := os.ReadFile(s); err == nil {
+		s = lastLine(string(b))
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != 42 || string(b[:2]) != "Ed" {
+		return nil, fmt.Errorf("invalid minisign public key")
+	}
+	k := &PublicKey{Key: ed25519.PublicKey(b[10:])}
+	copy(k.KeyID[:], b[2:10])
+	return k, nil
+}
+
+// VerifyMinisign checks a minisign signature of data, including the
+// signature of its trusted comment.
+func (r *PublicKey) VerifyMinisign(data, sig []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(sig)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(b) != 74 {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	if !bytes.Equal(b[2:10], r.KeyID[:]) {
+		return fmt.Errorf("signature was made with key %X, expected %X", b[2:10], r.KeyID[:])
+	}
+	signature := b[10:]
+	msg := data
+	switch string(b[:2]) {
+	case "Ed":
+	case "ED":
+		h := blake2b.Sum512(data)
+		msg = h[:]
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", b[:2])
+	}
+	if !ed25519.Verify(r.Key, msg, signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	comment := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+	if !ed25519.Verify(r.Key, append(append([]byte{}, signature...), comment...), global) {
+		return fmt.Errorf("invalid signature of the trusted comment")
+	}
+	return nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
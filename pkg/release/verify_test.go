@@ -0,0 +1,188 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestVerifySHA256(t *testing.T) {
+	data := []byte("kubenet")
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	tests := map[string]struct {
+		checksums string
+		name      string
+		wantErr   string
+	}{
+		"match": {
+			checksums: "0000  other.tar.gz\n" + digest + "  kubenet.tar.gz\n",
+			name:      "kubenet.tar.gz",
+		},
+		"binary mode": {
+			checksums: digest + " *kubenet.tar.gz\n",
+			name:      "kubenet.tar.gz",
+		},
+		"upper case digest": {
+			checksums: strings.ToUpper(digest) + "  kubenet.tar.gz\n",
+			name:      "kubenet.tar.gz",
+		},
+		"missing entry": {
+			checksums: digest + "  other.tar.gz\n",
+			name:      "kubenet.tar.gz",
+			wantErr:   "no checksum for kubenet.tar.gz",
+		},
+		"mismatch": {
+			checksums: strings.Repeat("0", 64) + "  kubenet.tar.gz\n",
+			name:      "kubenet.tar.gz",
+			wantErr:   "checksum mismatch for kubenet.tar.gz",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := VerifySHA256([]byte(tc.checksums), tc.name, data)
+			checkErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+// minisignKey returns a minisign key pair with the public key in the
+// format of a .pub file.
+func minisignKey(t *testing.T) (string, ed25519.PrivateKey, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := append(append([]byte("Ed"), keyID...), pub...)
+	return base64.StdEncoding.EncodeToString(b), priv, keyID
+}
+
+// minisign signs data like minisign does, prehashed with algorithm "ED".
+func minisign(priv ed25519.PrivateKey, keyID []byte, alg string, data []byte, comment string) string {
+	msg := data
+	if alg == "ED" {
+		h := blake2b.Sum512(data)
+		msg = h[:]
+	}
+	sig := ed25519.Sign(priv, msg)
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), comment...))
+	b := append(append([]byte(alg), keyID...), sig...)
+	return fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(b), comment, base64.StdEncoding.EncodeToString(global))
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	pub, priv, keyID := minisignKey(t)
+	file := filepath.Join(t.TempDir(), "kubenet.pub")
+	if err := os.WriteFile(file, []byte("untrusted comment: minisign public key\n"+pub+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePublicKey(file)
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	data := []byte("checksums")
+	otherKeyID := []byte{8, 7, 6, 5, 4, 3, 2, 1}
+
+	tests := map[string]struct {
+		sig     string
+		data    []byte
+		wantErr string
+	}{
+		"prehashed": {
+			sig: minisign(priv, keyID, "ED", data, "timestamp:1"),
+		},
+		"legacy": {
+			sig: minisign(priv, keyID, "Ed", data, "timestamp:1"),
+		},
+		"tampered data": {
+			sig:     minisign(priv, keyID, "ED", data, "timestamp:1"),
+			data:    []byte("checksums!"),
+			wantErr: "invalid signature",
+		},
+		"other key": {
+			sig:     minisign(priv, otherKeyID, "ED", data, "timestamp:1"),
+			wantErr: "signature was made with key 0807060504030201",
+		},
+		"tampered trusted comment": {
+			sig:     strings.Replace(minisign(priv, keyID, "ED", data, "timestamp:1"), "timestamp:1", "timestamp:2", 1),
+			wantErr: "invalid signature of the trusted comment",
+		},
+		"unsupported algorithm": {
+			sig:     minisign(priv, keyID, "XX", data, "timestamp:1"),
+			wantErr: `unsupported minisign algorithm "XX"`,
+		},
+		"malformed": {
+			sig:     "untrusted comment: signature\n",
+			wantErr: "invalid minisign signature",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := tc.data
+			if d == nil {
+				d = data
+			}
+			checkErr(t, key.VerifyMinisign(d, []byte(tc.sig)), tc.wantErr)
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := minisignKey(t)
+	if _, err := ParsePublicKey(pub); err != nil {
+		t.Errorf("ParsePublicKey(%q): %v", pub, err)
+	}
+	for name, s := range map[string]string{
+		"not base64": "!!!",
+		"too short":  base64.StdEncoding.EncodeToString([]byte("Ed1234")),
+		"other alg":  "XX" + pub[2:],
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParsePublicKey(s); err == nil {
+				t.Errorf("ParsePublicKey(%q) succeeded, expected an error", s)
+			}
+		})
+	}
+}
+
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error %v, expected one containing %q", err, want)
+	}
+}
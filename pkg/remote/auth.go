@@ -0,0 +1,149 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultKeys are the private keys tried in ~/.ssh, after the SSH agent.
+var defaultKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// config returns the client config of the target. Called with the lock
+// held.
+func (r *Client) config() (*ssh.ClientConfig, error) {
+	cfg := &ssh.ClientConfig{
+		User:            r.Target.User,
+		Auth:            r.Options.Auth,
+		HostKeyCallback: r.Options.HostKeyCallback,
+		Timeout:         r.Options.Timeout,
+	}
+	if cfg.Auth == nil {
+		auth, err := r.auth()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Auth = auth
+	}
+	if cfg.HostKeyCallback == nil {
+		path := r.Options.KnownHosts
+		if path == "" {
+			path = sshDir("known_hosts")
+		}
+		cb, err := knownhosts.New(path)
+		if err != nil {
+			return nil, exitcode.Preconditionf("cannot check the host key of %s: %w; connect once with ssh to add it to the known hosts", r.Target, err)
+		}
+		cfg.HostKeyCallback = cb
+		cfg.HostKeyAlgorithms = knownAlgorithms(cb, r.Target.Addr())
+	}
+	return cfg, nil
+}
+
+// auth returns the keys of the SSH agent, the identity file and the default
+// keys, the keys with a passphrase are left to the agent.
+func (r *Client) auth() ([]ssh.AuthMethod, error) {
+	methods := []ssh.AuthMethod{}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			r.closers = append(r.closers, conn)
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	signers := []ssh.Signer{}
+	if r.Options.IdentityFile != "" {
+		s, err := readKey(r.Options.IdentityFile)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, s)
+	} else {
+		for _, name := range defaultKeys {
+			if s, err := readKey(sshDir(name)); err == nil {
+				signers = append(signers, s)
+			}
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	if len(methods) == 0 {
+		return nil, exitcode.Preconditionf("no SSH key to authenticate to %s: start an SSH agent or set identity-file", r.Target)
+	}
+	return methods, nil
+}
+
+func readKey(path string) (ssh.Signer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("identity file: %w", err)
+	}
+	s, err := ssh.ParsePrivateKey(b)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("identity file %s has a passphrase, add it to the SSH agent", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("identity file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// knownAlgorithms returns the host key algorithms of the known keys of the
+// host, so the host offers a key the known hosts can check. It returns nil,
+// the default algorithms, when the host is not known.
+func knownAlgorithms(cb ssh.HostKeyCallback, addr string) []string {
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(cb(addr, &net.TCPAddr{IP: net.IPv4zero}, probe), &keyErr) {
+		return nil
+	}
+	algos := []string{}
+	for _, k := range keyErr.Want {
+		switch t := k.Key.Type(); t {
+		case ssh.KeyAlgoRSA:
+			algos = append(algos, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, t)
+		default:
+			algos = append(algos, t)
+		}
+	}
+	if len(algos) == 0 {
+		return nil
+	}
+	return algos
+}
+
+func sshDir(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".ssh", name)
+	}
+	return filepath.Join(home, ".ssh", name)
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remote runs the commands of the runbooks on another host over
+// SSH, e.g. a shared lab server.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/user"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultPort is the SSH port of a target without one.
+const DefaultPort = 22
+
+// Target is the host the commands run on, user@host[:port].
+type Target struct {
+	User string
+	Host string
+	Port int
+}
+
+// ParseTarget parses user@host[:port], the user defaults to the current
+// user.
+func ParseTarget(s string) (Target, error) {
+	t := Target{Host: s, Port: DefaultPort}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		t.User, t.Host = s[:i], s[i+1:]
+	}
+	if host, port, err := net.SplitHostPort(t.Host); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return Target{}, fmt.Errorf("invalid host %q: invalid port %q", s, port)
+		}
+		t.Host, t.Port = host, p
+	}
+	if t.Host == "" {
+		return Target{}, fmt.Errorf("invalid host %q, expected user@host[:port]", s)
+	}
+	if t.User == "" {
+		u, err := user.Current()
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid host %q, no user: %w", s, err)
+		}
+		t.User = u.Username
+	}
+	return t, nil
+}
+
+// Addr returns the host:port of the target.
+func (r Target) Addr() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+}
+
+func (r Target) String() string {
+	if r.Port == DefaultPort {
+		return r.User + "@" + r.Host
+	}
+	return r.User + "@" + r.Addr()
+}
+
+// Options configure how the client authenticates and checks the host.
+type Options struct {
+	// IdentityFile is a private key used in addition to the keys of the
+	// SSH agent and the default keys in ~/.ssh.
+	IdentityFile string
+	// KnownHosts is the known hosts file, ~/.ssh/known_hosts by default.
+	KnownHosts string
+	// Auth and HostKeyCallback replace the keys and the known hosts check,
+	// e.g. to connect to an in-process server.
+	Auth            []ssh.AuthMethod
+	HostKeyCallback ssh.HostKeyCallback
+	// Timeout bounds the connection setup.
+	Timeout time.Duration
+	// Kubeconfig is the kubeconfig of the commands on the target, relative
+	// to the home directory unless absolute. Empty keeps the kubeconfig of
+	// the remote user.
+	Kubeconfig string
+}
+
+// Client runs commands on the target. It connects on first use and reuses
+// the connection for the commands that follow, also concurrent ones.
+type Client struct {
+	Target  Target
+	Options Options
+
+	mu      sync.Mutex
+	client  *ssh.Client
+	home    string
+	closers []io.Closer
+	// uploaded maps the local bundles to their remote path
+	uploaded map[string]string
+}
+
+// New returns a client of the target.
+func New(target Target, opts Options) *Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = 15 * time.Second
+	}
+	return &Client{Target: target, Options: opts, uploaded: map[string]string{}}
+}
+
+// connect returns the connection to the target, dialing it once.
+func (r *Client) connect(ctx context.Context) (*ssh.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		return r.client, nil
+	}
+	cfg, err := r.config()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.Options.Timeout)
+	defer cancel()
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", r.Target.Addr())
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", r.Target, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, r.Target.Addr(), cfg)
+	if err != nil {
+		conn.Close()
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return nil, exitcode.Preconditionf("ssh %s: the host key is not known, connect once with ssh to add it to the known hosts", r.Target)
+		}
+		return nil, fmt.Errorf("ssh %s: %w", r.Target, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	r.client = ssh.NewClient(c, chans, reqs)
+	return r.client, nil
+}
+
+// Execute runs the command with the shell on the target and streams its
+// output. The error of a command that fails is an *ssh.ExitError.
+func (r *Client) Execute(ctx context.Context, cmd run.Command, stdout, stderr io.Writer) error {
+	c, err := r.connect(ctx)
+	if err != nil {
+		return err
+	}
+	cmd.Line, err = r.uploadBundles(ctx, cmd, stderr)
+	if err != nil {
+		return err
+	}
+	if r.Options.Kubeconfig != "" {
+		kubeconfig, err := r.Path(ctx, r.Options.Kubeconfig)
+		if err != nil {
+			return err
+		}
+		cmd.Env = append(slices.Clone(cmd.Env), "KUBECONFIG="+kubeconfig)
+	}
+	sess, err := c.NewSession()
+	if err != nil {
+		return fmt.Errorf("ssh %s: %w", r.Target, err)
+	}
+	defer sess.Close()
+	if stdout == stderr {
+		// the output of both streams is copied concurrently
+		stdout = &lockedWriter{w: stdout}
+		stderr = stdout
+	}
+	sess.Stdin = strings.NewReader(Script(cmd))
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+	if err := sess.Start(Line(cmd)); err != nil {
+		return fmt.Errorf("ssh %s: %w", r.Target, err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- sess.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		_ = sess.Signal(ssh.SIGKILL)
+		sess.Close()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Path returns the absolute path on the target of a path relative to the
+// home directory there. The home directory is looked up once.
+func (r *Client) Path(ctx context.Context, p string) (string, error) {
+	if path.IsAbs(p) {
+		return p, nil
+	}
+	c, err := r.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	home := r.home
+	r.mu.Unlock()
+	if home == "" {
+		sess, err := c.NewSession()
+		if err != nil {
+			return "", fmt.Errorf("ssh %s: %w", r.Target, err)
+		}
+		defer sess.Close()
+		out, err := sess.Output(`printf %s "$HOME"`)
+		if err != nil || len(out) == 0 || !path.IsAbs(string(out)) {
+			return "", fmt.Errorf("ssh %s: cannot look up the home directory: %v", r.Target, err)
+		}
+		home = string(out)
+		r.mu.Lock()
+		r.home = home
+		r.mu.Unlock()
+	}
+	return path.Join(home, p), nil
+}
+
+// Close closes the connection to the target.
+func (r *Client) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	if r.client != nil {
+		err = r.client.Close()
+		r.client = nil
+		r.home = ""
+	}
+	for _, c := range r.closers {
+		c.Close()
+	}
+	r.closers = nil
+	return err
+}
+
+// Line returns the command line of the command on the target, in allowlist
+// mode on top of an empty environment with the allowed variables of the
+// target. The values of the variables are not on the command line, where
+// the other users of the target see them: the line reads the Script of the
+// variables from stdin.
+func Line(cmd run.Command) string {
+	line := cmd.Shell + " -c " + Quote(cmd.Line)
+	if len(cmd.Env) > 0 {
+		line = "sh -c " + Quote(`eval "$(cat)" && exec `+line)
+	}
+	if cmd.Allow != nil {
+		env := []string{"env", "-i"}
+		for _, name := range cmd.Allow {
+			env = append(env, name+`="$`+name+`"`)
+		}
+		line = strings.Join(env, " ") + " " + line
+	}
+	if cmd.Dir != "" {
+		line = "cd " + Quote(cmd.Dir) + " && " + line
+	}
+	return line
+}
+
+// Script returns the script that exports the variables of the command, the
+// stdin of its Line.
+func Script(cmd run.Command) string {
+	b := &strings.Builder{}
+	for _, kv := range cmd.Env {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(b, "export %s=%s\n", name, Quote(value))
+	}
+	return b.String()
+}
+
+// Quote quotes the string for a POSIX shell.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (r *lockedWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Write(p)
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// server is an in-process SSH server that runs the exec requests with sh in
+// its home directory.
+type server struct {
+	target  Target
+	hostKey ssh.Signer
+	home    string
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	s := &server{hostKey: newSigner(t), home: t.TempDir()}
+	cfg := &ssh.ServerConfig{NoClientAuth: true}
+	cfg.AddHostKey(s.hostKey)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	addr := l.Addr().(*net.TCPAddr)
+	s.target = Target{User: "kubenet", Host: addr.IP.String(), Port: addr.Port}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, cfg)
+		}
+	}()
+	return s
+}
+
+func (r *server) serve(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+		go r.session(ch, reqs)
+	}
+}
+
+func (r *server) session(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			req.Reply(false, nil)
+			return
+		}
+		req.Reply(true, nil)
+		cmd := exec.Command("sh", "-c", payload.Command)
+		cmd.Dir = r.home
+		cmd.Env = []string{"HOME=" + r.home, "PATH=" + os.Getenv("PATH")}
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = ch, ch, ch.Stderr()
+		status := 0
+		if err := cmd.Run(); err != nil {
+			status = 255
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				status = exitErr.ExitCode()
+			}
+		}
+		ch.CloseWrite()
+		ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+		return
+	}
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// client returns a client of the server that trusts its host key.
+func (r *server) client(t *testing.T, opts Options) *Client {
+	t.Helper()
+	if opts.Auth == nil {
+		opts.Auth = []ssh.AuthMethod{}
+	}
+	if opts.HostKeyCallback == nil && opts.KnownHosts == "" {
+		opts.HostKeyCallback = ssh.FixedHostKey(r.hostKey.PublicKey())
+	}
+	c := New(r.target, opts)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestExecute(t *testing.T) {
+	s := newServer(t)
+	c := s.client(t, Options{Kubeconfig: ".config/kubenet/profiles/lab/kubeconfig"})
+
+	tests := map[string]struct {
+		line           string
+		env, allow     []string
+		stdout, stderr string
+		exitCode       int
+	}{
+		"success":   {line: "echo out", stdout: "out\n"},
+		"streams":   {line: "echo out; echo err >&2", stdout: "out\n", stderr: "err\n"},
+		"exit code": {line: "echo failed >&2; exit 3", stderr: "failed\n", exitCode: 3},
+		"home":      {line: `printf %s "$KUBECONFIG"`, stdout: path.Join(s.home, ".config/kubenet/profiles/lab/kubeconfig")},
+		"env": {
+			line:   `printf '%s|%s' "$SDC_PASSWORD" "$NOTE"`,
+			env:    []string{"SDC_PASSWORD=it's s3cr3t", "NOTE=line1\nline2 $HOME"},
+			stdout: "it's s3cr3t|line1\nline2 $HOME",
+		},
+		"allowlist": {
+			line:   `printf '%s|%s|%s' "$HOME" "$USER" "$SDC_PASSWORD"`,
+			env:    []string{"SDC_PASSWORD=s3cr3t"},
+			allow:  []string{"PATH", "HOME"},
+			stdout: s.home + "||s3cr3t",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			cmd := run.Command{Shell: "sh", Line: tc.line, Env: tc.env, Allow: tc.allow}
+			err := c.Execute(context.Background(), cmd, stdout, stderr)
+			if tc.exitCode == 0 && err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tc.exitCode != 0 {
+				var exitErr *ssh.ExitError
+				if !errors.As(err, &exitErr) || exitErr.ExitStatus() != tc.exitCode {
+					t.Fatalf("Execute() error = %v, want exit status %d", err, tc.exitCode)
+				}
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("stdout = %q, want %q", stdout, tc.stdout)
+			}
+			if stderr.String() != tc.stderr {
+				t.Errorf("stderr = %q, want %q", stderr, tc.stderr)
+			}
+		})
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := map[string]run.Command{
+		"env":       {Shell: "bash", Line: "kubectl apply -f -", Env: []string{"SDC_PASSWORD=s3cr3t"}},
+		"allowlist": {Shell: "bash", Line: "true", Env: []string{"SDC_PASSWORD=s3cr3t"}, Allow: []string{"PATH"}},
+		"dir":       {Shell: "bash", Line: "true", Env: []string{"SDC_PASSWORD=s3cr3t"}, Dir: "/tmp"},
+	}
+	for name, cmd := range tests {
+		t.Run(name, func(t *testing.T) {
+			if line := Line(cmd); strings.Contains(line, "s3cr3t") {
+				t.Errorf("Line() = %q contains the secret", line)
+			}
+			if script := Script(cmd); !strings.Contains(script, "export SDC_PASSWORD='s3cr3t'") {
+				t.Errorf("Script() = %q does not export the secret", script)
+			}
+		})
+	}
+}
+
+func TestUploadBundles(t *testing.T) {
+	s := newServer(t)
+	c := s.client(t, Options{})
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "manifests"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifests", "vpc.yaml"), []byte("kind: Network\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the local path is relative to the directory of the command, the
+	// remote path is absolute as the line changes to that directory first
+	cmd := run.Command{Shell: "sh", Line: "kubectl apply -f manifests", Dir: dir}
+	line, err := c.uploadBundles(context.Background(), cmd, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("uploadBundles() error = %v", err)
+	}
+	remote := strings.Trim(strings.TrimPrefix(line, "kubectl apply -f "), "'")
+	if !strings.HasPrefix(remote, path.Join(s.home, BundleDir)+"/") || path.Base(remote) != "manifests" {
+		t.Fatalf("uploadBundles() = %q, want the manifests in %s", line, path.Join(s.home, BundleDir))
+	}
+	b, err := os.ReadFile(filepath.Join(remote, "vpc.yaml"))
+	if err != nil || string(b) != "kind: Network\n" {
+		t.Fatalf("uploaded vpc.yaml = %q, %v", b, err)
+	}
+
+	again, err := c.uploadBundles(context.Background(), cmd, &bytes.Buffer{})
+	if err != nil || again != line {
+		t.Errorf("second uploadBundles() = %q, %v, want %q", again, err, line)
+	}
+
+	// the path is rewritten whatever the white space around it, the rest of
+	// the line is kept
+	for _, tc := range []struct{ line, want string }{
+		{"kubectl apply -f\tmanifests", "kubectl apply -f\t'" + remote + "'"},
+		{"kubectl apply  -f   manifests  -n lab", "kubectl apply  -f   '" + remote + "'  -n lab"},
+		{"kubectl apply -f manifests && kubectl apply --kustomize manifests", "kubectl apply -f '" + remote + "' && kubectl apply --kustomize '" + remote + "'"},
+	} {
+		got, err := c.uploadBundles(context.Background(), run.Command{Line: tc.line, Dir: dir}, &bytes.Buffer{})
+		if err != nil || got != tc.want {
+			t.Errorf("uploadBundles(%q) = %q, %v, want %q", tc.line, got, err, tc.want)
+		}
+	}
+
+	for _, line := range []string{"kubectl apply -f missing", "kubectl apply -f https://example.com/vpc.yaml"} {
+		got, err := c.uploadBundles(context.Background(), run.Command{Line: line, Dir: dir}, &bytes.Buffer{})
+		if err != nil || got != line {
+			t.Errorf("uploadBundles(%q) = %q, %v, want it unchanged", line, got, err)
+		}
+	}
+}
+
+func TestHostKey(t *testing.T) {
+	s := newServer(t)
+
+	tests := map[string]struct {
+		known        ssh.PublicKey
+		precondition bool
+	}{
+		"unknown host": {precondition: true},
+		"changed key":  {known: newSigner(t).PublicKey()},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "known_hosts")
+			content := ""
+			if tc.known != nil {
+				content = knownhosts.Line([]string{s.target.Addr()}, tc.known) + "\n"
+			}
+			if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			c := s.client(t, Options{KnownHosts: file})
+			err := c.Execute(context.Background(), run.Command{Shell: "sh", Line: "true"}, &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil {
+				t.Fatal("Execute() succeeded, want the host key rejected")
+			}
+			if got := exitcode.For(err) == exitcode.Precondition; got != tc.precondition {
+				t.Errorf("Execute() error = %v, precondition %t, want %t", err, got, tc.precondition)
+			}
+		})
+	}
+
+	t.Run("known key", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "known_hosts")
+		if err := os.WriteFile(file, []byte(knownhosts.Line([]string{s.target.Addr()}, s.hostKey.PublicKey())+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c := s.client(t, Options{KnownHosts: file})
+		if err := c.Execute(context.Background(), run.Command{Shell: "sh", Line: "true"}, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+)
+
+// BundleDir is the directory the local files of the commands are uploaded
+// to, relative to the home directory on the target.
+const BundleDir = ".kubenet/bundles"
+
+// fileFlags are the kubectl flags that take a local file or directory.
+var fileFlags = map[string]bool{"-f": true, "--filename": true, "-k": true, "--kustomize": true}
+
+// uploadBundles uploads the local files and directories the command applies
+// and returns its line with their absolute remote path, the line may change
+// directory first. Relative local paths are resolved against the directory
+// of the command. URLs and paths that do not exist locally are left as they
+// are.
+func (r *Client) uploadBundles(ctx context.Context, cmd run.Command, log io.Writer) (string, error) {
+	line := cmd.Line
+	fields := fieldIndexes(line)
+	b := &strings.Builder{}
+	end := 0
+	for i := 0; i < len(fields)-1; i++ {
+		flag, arg := line[fields[i][0]:fields[i][1]], line[fields[i+1][0]:fields[i+1][1]]
+		if !fileFlags[flag] || strings.Contains(arg, "://") {
+			continue
+		}
+		local := arg
+		if !filepath.IsAbs(local) && cmd.Dir != "" {
+			local = filepath.Join(cmd.Dir, local)
+		}
+		if _, err := os.Stat(local); err != nil {
+			continue
+		}
+		remote, err := r.Upload(ctx, local)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(log, "uploaded %s to %s:%s\n", local, r.Target, remote)
+		// the path is replaced where the field is, the rest of the line is
+		// kept as it is
+		b.WriteString(line[end:fields[i+1][0]])
+		b.WriteString(Quote(remote))
+		end = fields[i+1][1]
+	}
+	b.WriteString(line[end:])
+	return b.String(), nil
+}
+
+// fieldIndexes returns the start and end offsets of the fields of the line
+// separated by white space, like strings.Fields.
+func fieldIndexes(line string) [][2]int {
+	fields := [][2]int{}
+	start := -1
+	for i, c := range line {
+		switch {
+		case unicode.IsSpace(c) && start >= 0:
+			fields = append(fields, [2]int{start, i})
+			start = -1
+		case !unicode.IsSpace(c) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, [2]int{start, len(line)})
+	}
+	return fields
+}
+
+// Upload copies the local file or directory to the bundle directory on the
+// target and returns its absolute remote path. A bundle is named after its
+// content, it is uploaded once.
+func (r *Client) Upload(ctx context.Context, local string) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := bundle(buf, local); err != nil {
+		return "", fmt.Errorf("bundle %s: %w", local, err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	dir, err := r.Path(ctx, path.Join(BundleDir, hex.EncodeToString(sum[:6])))
+	if err != nil {
+		return "", err
+	}
+	remote := path.Join(dir, filepath.Base(filepath.Clean(local)))
+
+	r.mu.Lock()
+	done := r.uploaded[remote] != ""
+	r.mu.Unlock()
+	if done {
+		return remote, nil
+	}
+
+	c, err := r.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	sess, err := c.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("ssh %s: %w", r.Target, err)
+	}
+	defer sess.Close()
+	sess.Stdin = buf
+	out := &bytes.Buffer{}
+	// the output of both streams is copied concurrently
+	w := &lockedWriter{w: out}
+	sess.Stdout, sess.Stderr = w, w
+	if err := sess.Run("mkdir -p " + Quote(dir) + " && tar -xf - -C " + Quote(dir)); err != nil {
+		return "", fmt.Errorf("upload %s to %s: %w: %s", local, r.Target, err, strings.TrimSpace(out.String()))
+	}
+
+	r.mu.Lock()
+	r.uploaded[remote] = local
+	r.mu.Unlock()
+	return remote, nil
+}
+
+// bundle writes the file or directory as a tar archive, under its base
+// name.
+func bundle(w io.Writer, local string) error {
+	local = filepath.Clean(local)
+	base := filepath.Dir(local)
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(local, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		// the bundle is named after its content, not after when and by
+		// whom the files were written
+		hdr.ModTime = time.Unix(0, 0)
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.Mode().IsDir() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
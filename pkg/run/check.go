@@ -0,0 +1,192 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+const (
+	// DefaultVerifyTimeout is the deadline of the checks of a run.
+	DefaultVerifyTimeout = 5 * time.Minute
+	// DefaultVerifyInterval is the pause between the attempts of a check.
+	DefaultVerifyInterval = 5 * time.Second
+)
+
+type check struct {
+	text, command []string
+}
+
+// Check adds a verification check to the run. The checks run after the
+// steps and are retried until they pass or the deadline expires; a check
+// passes when its command exits with 0.
+func (r *Run) Check(text, command []string) {
+	r.checks = append(r.checks, check{text: text, command: command})
+}
+
+// HasChecks returns true when the run has verification checks.
+func (r *Run) HasChecks() bool {
+	return len(r.checks) > 0
+}
+
+// CheckResult is the outcome of a check.
+type CheckResult struct {
+	Name     string        `json:"name" yaml:"name"`
+	Command  string        `json:"command" yaml:"command"`
+	Passed   bool          `json:"passed" yaml:"passed"`
+	Attempts int           `json:"attempts" yaml:"attempts"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	// Output is the output of the last failed attempt.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// Report is the outcome of the checks of a run.
+type Report struct {
+	Runbook string        `json:"runbook" yaml:"runbook"`
+	Passed  bool          `json:"passed" yaml:"passed"`
+	Checks  []CheckResult `json:"checks" yaml:"checks"`
+}
+
+// VerifyOptions specify how long the checks are retried.
+type VerifyOptions struct {
+	Timeout  time.Duration
+	Interval time.Duration
+	Shell    string
+	// Executor runs the checks, the executor of the context by default.
+	Executor Executor
+}
+
+// Verify runs the checks until they all pass or the deadline expires. The
+// checks that passed are not run again.
+func (r *Run) Verify(ctx context.Context, opts VerifyOptions) (*Report, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultVerifyTimeout
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultVerifyInterval
+	}
+	if opts.Shell == "" {
+		opts.Shell = "bash"
+	}
+	if opts.Executor == nil {
+		opts.Executor = executor(ctx)
+	}
+	if allow := getContextValue[[]string](ctx, CtxKeyEnvAllowlist); len(allow) > 0 && r.allow == nil {
+		r.AllowEnv(allow...)
+	}
+	r.registerSecrets()
+	report := &Report{Runbook: r.title}
+	for _, c := range r.checks {
+		report.Checks = append(report.Checks, CheckResult{
+			Name:    r.secrets.redact(strings.Join(c.text, " ")),
+			Command: r.secrets.redact(strings.Join(c.command, " ")),
+		})
+	}
+
+	start := time.Now()
+	deadline, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	for {
+		pending := 0
+		for i := range report.Checks {
+			res := &report.Checks[i]
+			if res.Passed {
+				continue
+			}
+			res.Attempts++
+			var out bytes.Buffer
+			cmd := r.command(strings.Join(r.checks[i].command, " "), nil, "")
+			cmd.Shell = opts.Shell
+			err := opts.Executor.Execute(deadline, cmd, &out, &out)
+			res.Duration = time.Since(start)
+			if err == nil {
+				res.Passed, res.Output = true, ""
+				continue
+			}
+			if len(bytes.TrimSpace(out.Bytes())) > 0 {
+				res.Output = r.secrets.redact(string(bytes.TrimSpace(out.Bytes())))
+			} else {
+				res.Output = err.Error()
+			}
+			pending++
+		}
+		if pending == 0 {
+			report.Passed = true
+			return report, nil
+		}
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-deadline.Done():
+			return report, &exitcode.VerifyError{Runbook: r.title, Failed: pending, Timeout: opts.Timeout}
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// Write prints the report, one line per check.
+func (r *Report) Write(out io.Writer, noColor bool) error {
+	pass, fail := color.Green.Sprintf, color.Red.Sprintf
+	if noColor {
+		pass, fail = fmt.Sprintf, fmt.Sprintf
+	}
+	for _, c := range r.Checks {
+		attempts := "attempt"
+		if c.Attempts != 1 {
+			attempts += "s"
+		}
+		if c.Passed {
+			if _, err := fmt.Fprintf(out, "%s %s (%d %s, %s)\n", pass("PASS"), c.Name, c.Attempts, attempts, c.Duration.Round(time.Millisecond)); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "%s %s (%d %s)\n", fail("FAIL"), c.Name, c.Attempts, attempts); err != nil {
+			return err
+		}
+		for _, line := range strings.Split(c.Output, "\n") {
+			if _, err := fmt.Fprintf(out, "     %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// verify runs the checks of the run after its steps.
+func (r *Run) verify(ctx context.Context, timeout time.Duration) error {
+	p := color.Cyan.Sprintf
+	if r.options.NoColor {
+		p = fmt.Sprintf
+	}
+	if err := write(r.out, p("Verify\n")); err != nil {
+		return err
+	}
+	report, err := r.Verify(ctx, VerifyOptions{Timeout: timeout, Shell: r.options.Shell, Executor: r.exec})
+	if werr := report.Write(r.out, r.options.NoColor); werr != nil {
+		return werr
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+// flakyExecutor fails a command line the given number of times, then it
+// passes.
+type flakyExecutor struct {
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func (r *flakyExecutor) Execute(_ context.Context, cmd Command, stdout, _ io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures[cmd.Line] != 0 {
+		r.failures[cmd.Line]--
+		io.WriteString(stdout, "not ready\n")
+		return exitStatus(1)
+	}
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	tests := map[string]struct {
+		failures map[string]int
+		passed   bool
+		attempts []int
+		failed   int
+	}{
+		"pass":  {failures: map[string]int{}, passed: true, attempts: []int{1, 1}},
+		"retry": {failures: map[string]int{"nodes": 2}, passed: true, attempts: []int{3, 1}},
+		// a check that passed is not run again
+		"timeout": {failures: map[string]int{"nodes": 1, "lab": 1000}, attempts: []int{2, 0}, failed: 1},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			x := newTestRun("Setup", io.Discard)
+			x.Check(S("the nodes are ready"), S("nodes"))
+			x.Check(S("the lab is running"), S("lab"))
+			report, err := x.Verify(context.Background(), VerifyOptions{
+				Timeout:  100 * time.Millisecond,
+				Interval: 10 * time.Millisecond,
+				Executor: &flakyExecutor{failures: tc.failures},
+			})
+			if report.Passed != tc.passed {
+				t.Errorf("Passed = %t, want %t", report.Passed, tc.passed)
+			}
+			if report.Checks[0].Attempts != tc.attempts[0] || (tc.attempts[1] > 0 && report.Checks[1].Attempts != tc.attempts[1]) {
+				t.Errorf("attempts = %d, %d, want %v", report.Checks[0].Attempts, report.Checks[1].Attempts, tc.attempts)
+			}
+			if tc.passed {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				return
+			}
+			var verr *exitcode.VerifyError
+			if !errors.As(err, &verr) || verr.Failed != tc.failed || verr.Runbook != "Setup" {
+				t.Fatalf("Verify() error = %v, want a verify error of %d check", err, tc.failed)
+			}
+			if got := exitcode.For(err); got != exitcode.VerifyFailed {
+				t.Errorf("exit code = %d, want %d", got, exitcode.VerifyFailed)
+			}
+			if report.Checks[1].Output != "not ready" {
+				t.Errorf("Output = %q, want the output of the last attempt", report.Checks[1].Output)
+			}
+		})
+	}
+}
+
+func TestRunVerify(t *testing.T) {
+	tests := map[string]struct {
+		skip    bool
+		wantErr bool
+	}{
+		"failed check": {wantErr: true},
+		"skip verify":  {skip: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &strings.Builder{}
+			x := newTestRun("Setup", out)
+			x.Step(S("create the cluster"), S("kind create cluster"))
+			x.Check(S("the nodes are ready"), S("nodes"))
+			ctx := context.WithValue(testContext(&fakeExecutor{fail: map[string]int{"nodes": 1}}), CtxKeyVerifyTimeout, 50*time.Millisecond)
+			ctx = context.WithValue(ctx, CtxKeySkipVerify, tc.skip)
+
+			err := x.Run(ctx)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				return
+			}
+			if got := exitcode.For(err); got != exitcode.VerifyFailed {
+				t.Errorf("Run() error = %v, exit code %d, want %d", err, got, exitcode.VerifyFailed)
+			}
+			if !strings.Contains(out.String(), "FAIL the nodes are ready (1 attempt)\n     ran nodes\n") {
+				t.Errorf("the report is not in the output:\n%s", out)
+			}
+		})
+	}
+}
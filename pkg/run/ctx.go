@@ -25,4 +25,21 @@ func (c CtxKey) String() string {
 const (
 	CtxKeyAutomatic CtxKey = "auto"
 	CtxKeyShell     CtxKey = "shell"
+	CtxKeyDiff      CtxKey = "diff"
+	CtxKeyRecord    CtxKey = "record"
+	CtxKeyStepDelay CtxKey = "stepdelay"
+	CtxKeyParallel  CtxKey = "parallel"
+	// CtxKeySkipVerify skips the checks after the steps of a run.
+	CtxKeySkipVerify CtxKey = "skipverify"
+	// CtxKeyVerifyTimeout is the deadline of the checks of a run.
+	CtxKeyVerifyTimeout CtxKey = "verifytimeout"
+	// CtxKeyRunbooks is the Resolver of the runbooks of the exercises.
+	CtxKeyRunbooks CtxKey = "runbooks"
+	// CtxKeyWithDeps runs the exercises a run requires that are not done.
+	CtxKeyWithDeps CtxKey = "withdeps"
+	// CtxKeyExecutor is the Executor of the commands, e.g. on a remote host.
+	CtxKeyExecutor CtxKey = "executor"
+	// CtxKeyEnvAllowlist switches the runs to allowlist mode with the
+	// inherited variables it lists.
+	CtxKeyEnvAllowlist CtxKey = "envallowlist"
 )
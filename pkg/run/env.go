@@ -0,0 +1,202 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the secrets in the output of a run.
+const Redacted = "******"
+
+// AlwaysAllowed are the inherited variables that are kept in allowlist
+// mode, KUBECONFIG points to the cluster of the profile.
+var AlwaysAllowed = []string{"PATH", "HOME", "KUBECONFIG"}
+
+type envVar struct {
+	name, value string
+	secret      bool
+}
+
+// SetEnv sets a variable for the commands of the run. The value is
+// expanded: $NAME and ${NAME} refer to the variables set before it and to
+// the inherited environment.
+func (r *Run) SetEnv(name, value string) {
+	r.env = append(r.env, envVar{name: name, value: value})
+}
+
+// SetSecretEnv sets a variable whose value is redacted from the output and
+// the recording of the run. The value is a credential, it is not expanded.
+func (r *Run) SetSecretEnv(name, value string) {
+	r.env = append(r.env, envVar{name: name, value: value, secret: true})
+}
+
+// SetWorkDir sets the working directory of the commands of the run, it is
+// expanded like the variables and a leading ~ is the home directory.
+func (r *Run) SetWorkDir(dir string) {
+	r.dir = dir
+}
+
+// AllowEnv switches the run to allowlist mode: the commands only inherit
+// the named variables and the AlwaysAllowed ones, besides the variables
+// set for the run and its steps.
+func (r *Run) AllowEnv(names ...string) {
+	if r.allow == nil {
+		r.allow = append([]string{}, AlwaysAllowed...)
+	}
+	r.allow = append(r.allow, names...)
+}
+
+// Redact redacts the values from the output and the recording of the run,
+// e.g. a password substituted in a command.
+func (r *Run) Redact(values ...string) {
+	r.secrets.add(values...)
+}
+
+// Env sets a variable for the command of the step, it overrides the
+// variable of the run and is expanded like it.
+func Env(name, value string) StepOption {
+	return func(s *step) {
+		s.env = append(s.env, envVar{name: name, value: value})
+	}
+}
+
+// SecretEnv sets a variable for the command of the step like
+// SetSecretEnv, its value is redacted and not expanded.
+func SecretEnv(name, value string) StepOption {
+	return func(s *step) {
+		s.env = append(s.env, envVar{name: name, value: value, secret: true})
+	}
+}
+
+// WorkDir sets the working directory of the command of the step.
+func WorkDir(dir string) StepOption {
+	return func(s *step) {
+		s.dir = dir
+	}
+}
+
+// command returns the command line with the environment and working
+// directory of the run and the step.
+func (r *Run) command(line string, env []envVar, dir string) Command {
+	vars := map[string]string{}
+	lookup := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+	cmd := Command{Shell: r.options.Shell, Line: line, Allow: r.allow}
+	for _, e := range append(append([]envVar{}, r.env...), env...) {
+		v := e.value
+		if !e.secret {
+			v = os.Expand(v, lookup)
+		}
+		vars[e.name] = v
+		cmd.Env = append(cmd.Env, e.name+"="+v)
+		if e.secret {
+			r.secrets.add(v)
+		}
+	}
+	if dir == "" {
+		dir = r.dir
+	}
+	cmd.Dir = expandPath(os.Expand(dir, lookup))
+	return cmd
+}
+
+// registerSecrets expands the secret variables of the run and its steps, so
+// they are redacted before the first command runs.
+func (r *Run) registerSecrets() {
+	r.command("", nil, "")
+	for _, s := range r.steps {
+		r.command("", s.env, s.dir)
+	}
+}
+
+func expandPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+	}
+	return p
+}
+
+// secrets are the values redacted from the output of a run.
+type secrets struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+func (r *secrets) add(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		if v != "" && !slices.Contains(r.values, v) {
+			r.values = append(r.values, v)
+		}
+	}
+	// a secret that contains another one is replaced first
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+func (r *secrets) redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+	return s
+}
+
+// redactWriter redacts the secrets from the output of a command. The
+// output is written line by line so a secret is not split between writes.
+type redactWriter struct {
+	secrets *secrets
+	out     io.Writer
+	buf     []byte
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	if i := bytes.LastIndexByte(r.buf, '\n'); i >= 0 {
+		line := r.buf[:i+1]
+		r.buf = append([]byte{}, r.buf[i+1:]...)
+		if _, err := io.WriteString(r.out, r.secrets.redact(string(line))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the output that does not end with a newline.
+func (r *redactWriter) Flush() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(r.out, r.secrets.redact(string(r.buf)))
+	r.buf = nil
+	return err
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := map[string]struct {
+		values []string
+		in     string
+		want   string
+	}{
+		"no secrets":  {in: "password=s3cr3t", want: "password=s3cr3t"},
+		"secret":      {values: []string{"s3cr3t"}, in: "password=s3cr3t", want: "password=" + Redacted},
+		"every match": {values: []string{"s3cr3t"}, in: "s3cr3t s3cr3t", want: Redacted + " " + Redacted},
+		"empty value": {values: []string{""}, in: "password=", want: "password="},
+		// the longer secret is replaced before the one it contains
+		"overlapping": {values: []string{"admin", "admin123"}, in: "admin:admin123", want: Redacted + ":" + Redacted},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &secrets{}
+			s.add(tc.values...)
+			if got := s.redact(tc.in); got != tc.want {
+				t.Errorf("redact(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRedactWriter(t *testing.T) {
+	tests := map[string]struct {
+		writes []string
+		// want is the output after each write and after the flush
+		want []string
+	}{
+		"line": {
+			writes: []string{"token s3cr3t\n"},
+			want:   []string{"token " + Redacted + "\n", "token " + Redacted + "\n"},
+		},
+		"secret split between writes": {
+			writes: []string{"token s3", "cr3t\n"},
+			want:   []string{"", "token " + Redacted + "\n", "token " + Redacted + "\n"},
+		},
+		"lines in one write": {
+			writes: []string{"a s3cr3t\nb s3cr3t\nc s3"},
+			want:   []string{"a " + Redacted + "\nb " + Redacted + "\n", "a " + Redacted + "\nb " + Redacted + "\nc s3"},
+		},
+		"flush without newline": {
+			writes: []string{"s3cr3t"},
+			want:   []string{"", Redacted},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &secrets{}
+			s.add("s3cr3t")
+			out := &strings.Builder{}
+			w := &redactWriter{secrets: s, out: out}
+			for i, p := range tc.writes {
+				if n, err := w.Write([]byte(p)); err != nil || n != len(p) {
+					t.Fatalf("Write(%q) = %d, %v", p, n, err)
+				}
+				if got := out.String(); got != tc.want[i] {
+					t.Errorf("after Write(%q) output = %q, want %q", p, got, tc.want[i])
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got, want := out.String(), tc.want[len(tc.want)-1]; got != want {
+				t.Errorf("after Flush() output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	inherited := []string{"PATH=/bin", "HOME=/root", "AWS_SECRET=x", "LAB=3node"}
+
+	tests := map[string]struct {
+		cmd  Command
+		want []string
+	}{
+		"inherit all": {
+			cmd:  Command{Env: []string{"NS=kubenet"}},
+			want: []string{"PATH=/bin", "HOME=/root", "AWS_SECRET=x", "LAB=3node", "NS=kubenet"},
+		},
+		"allowlist": {
+			cmd:  Command{Allow: []string{"PATH", "HOME", "LAB"}, Env: []string{"NS=kubenet"}},
+			want: []string{"PATH=/bin", "HOME=/root", "LAB=3node", "NS=kubenet"},
+		},
+		"empty allowlist": {
+			cmd:  Command{Allow: []string{}},
+			want: []string{},
+		},
+		// the variables of the command come last and override the inherited
+		"override": {
+			cmd:  Command{Allow: []string{"LAB"}, Env: []string{"LAB=5node"}},
+			want: []string{"LAB=3node", "LAB=5node"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.cmd.Environ(inherited); !slices.Equal(got, tc.want) {
+				t.Errorf("Environ() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommandExpand(t *testing.T) {
+	t.Setenv("KUBENET_TEST_LAB", "3node")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		run     []envVar
+		step    []envVar
+		dir     string
+		want    []string
+		wantDir string
+		secrets []string
+	}{
+		"inherited": {
+			run:  []envVar{{name: "TOPO", value: "lab/$KUBENET_TEST_LAB.yaml"}},
+			want: []string{"TOPO=lab/3node.yaml"},
+		},
+		"earlier variable": {
+			run:  []envVar{{name: "NS", value: "kubenet"}, {name: "CTX", value: "kind-${NS}"}},
+			want: []string{"NS=kubenet", "CTX=kind-kubenet"},
+		},
+		// a variable refers to the ones set before it, not after it
+		"later variable": {
+			run:  []envVar{{name: "CTX", value: "kind-$NS"}, {name: "NS", value: "kubenet"}},
+			want: []string{"CTX=kind-", "NS=kubenet"},
+		},
+		"step after run": {
+			run:  []envVar{{name: "NS", value: "kubenet"}},
+			step: []envVar{{name: "NS", value: "$NS-test"}},
+			want: []string{"NS=kubenet", "NS=kubenet-test"},
+		},
+		"secret is not expanded": {
+			run:     []envVar{{name: "PASS", value: "pa$$word", secret: true}, {name: "USER", value: "admin:$PASS"}},
+			want:    []string{"PASS=pa$$word", "USER=admin:pa$$word"},
+			secrets: []string{"pa$$word"},
+		},
+		"dir": {
+			run:     []envVar{{name: "LAB", value: "labs/$KUBENET_TEST_LAB"}},
+			dir:     "~/$LAB",
+			want:    []string{"LAB=labs/3node"},
+			wantDir: filepath.Join(home, "labs/3node"),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRun("test")
+			r.env = tc.run
+			cmd := r.command("true", tc.step, tc.dir)
+			if !slices.Equal(cmd.Env, tc.want) {
+				t.Errorf("Env = %v, want %v", cmd.Env, tc.want)
+			}
+			if cmd.Dir != tc.wantDir {
+				t.Errorf("Dir = %q, want %q", cmd.Dir, tc.wantDir)
+			}
+			for _, s := range tc.secrets {
+				if got := r.secrets.redact(s); got != Redacted {
+					t.Errorf("secret %q is not redacted: %q", s, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Executor runs the commands of the steps and checks of a run.
+type Executor interface {
+	// Execute runs the command and streams its output.
+	Execute(ctx context.Context, cmd Command, stdout, stderr io.Writer) error
+}
+
+// Command is a command line of a step or check, run with the shell in its
+// environment and working directory.
+type Command struct {
+	Shell string
+	Line  string
+	// Env are the NAME=value variables of the run and the step.
+	Env []string
+	// Allow lists the inherited variables in allowlist mode, nil inherits
+	// all variables.
+	Allow []string
+	// Dir is the working directory, "" is the current directory.
+	Dir string
+}
+
+// Environ returns the environment of the command from the inherited
+// variables.
+func (r Command) Environ(inherited []string) []string {
+	env := []string{}
+	for _, kv := range inherited {
+		name, _, _ := strings.Cut(kv, "=")
+		if r.Allow == nil || slices.Contains(r.Allow, name) {
+			env = append(env, kv)
+		}
+	}
+	return append(env, r.Env...)
+}
+
+// HostExecutor is an executor that does not run the commands on the host,
+// e.g. in a container. The steps marked with HostOnly run with the executor
+// of its Host method.
+type HostExecutor interface {
+	Executor
+	Host() Executor
+}
+
+// Local runs the commands on this machine.
+type Local struct{}
+
+// Execute runs the command with the shell on this machine.
+func (Local) Execute(ctx context.Context, c Command, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, c.Shell, "-c", c.Line) //nolint:gosec // we purposefully run user-provided code
+	cmd.Env = c.Environ(os.Environ())
+	cmd.Dir = c.Dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// executor returns the executor of the context, commands run locally when
+// there is none.
+func executor(ctx context.Context) Executor {
+	if e := getContextValue[Executor](ctx, CtxKeyExecutor); e != nil {
+		return e
+	}
+	return Local{}
+}
+
+// exitCode returns the exit code of a failed command, -1 when it did not
+// exit normally.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	// e.g. the exit of a remote command
+	var statusErr interface{ ExitStatus() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.ExitStatus()
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gookit/color"
+)
+
+// DefaultParallel is the number of steps of a group that run concurrently.
+const DefaultParallel = 4
+
+// runGroup runs the steps of a group concurrently, at most Parallel at a
+// time. The steps are announced in order and their output lines are
+// prefixed with the step number. When a step fails the steps that did not
+// start yet are skipped; the steps that are running complete. The same
+// holds when the run is interrupted.
+func (r *Run) runGroup(ctx context.Context, first int, steps []step) error {
+	max := len(r.steps)
+	for i := range steps {
+		s := &steps[i]
+		if err := s.waitOrSleep(); err != nil {
+			return fmt.Errorf("unable to run step: %v: %w", s, err)
+		}
+		if len(s.text) > 0 && !r.options.HideDescriptions {
+			s.echo(first+i, max)
+		}
+		if len(s.command) > 0 {
+			s.printCommand()
+		}
+	}
+
+	limit := r.options.Parallel
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	errs := make([]error, len(steps))
+	var failed atomic.Bool
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := range steps {
+		if len(steps[i].command) == 0 {
+			continue
+		}
+		s := steps[i]
+		w := &prefixWriter{out: r.out, mu: &mu, prefix: r.label(first+i, max)}
+		s.out = w
+		// steps start in order
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			fmt.Fprintln(w, "skipped, the run was interrupted")
+			continue
+		}
+		if failed.Load() {
+			<-sem
+			fmt.Fprintln(w, "skipped, an earlier step of the group failed")
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer w.Flush()
+			if err := s.runCommand(ctx, first+i, max); err != nil {
+				failed.Store(true)
+				errs[i] = err
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := write(r.out, "\n"); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("runbook %q stopped at step %d/%d: %w", r.title, first, max, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Run) label(current, max int) string {
+	p := color.Magenta.Sprintf
+	if r.options.NoColor {
+		p = fmt.Sprintf
+	}
+	return p("[%d/%d]", current, max) + " "
+}
+
+// prefixWriter writes complete lines with a prefix, so the lines of
+// concurrent steps do not interleave.
+type prefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (r *prefixWriter) Write(b []byte) (int, error) {
+	r.buf.Write(b)
+	for {
+		i := bytes.IndexByte(r.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		line := r.buf.Next(i + 1)
+		if err := r.writeLine(line); err != nil {
+			return len(b), err
+		}
+	}
+}
+
+// Flush writes an incomplete last line.
+func (r *prefixWriter) Flush() {
+	if r.buf.Len() > 0 {
+		_ = r.writeLine(append(r.buf.Bytes(), '\n'))
+		r.buf.Reset()
+	}
+}
+
+func (r *prefixWriter) writeLine(line []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := io.WriteString(r.out, r.prefix+string(line))
+	return err
+}
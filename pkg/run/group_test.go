@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+// groupRun returns a run with a group of steps that run step1..stepN.
+func groupRun(n int) (*Run, *strings.Builder) {
+	out := &strings.Builder{}
+	x := newTestRun("Group", out)
+	for i := 1; i <= n; i++ {
+		x.Step(S(fmt.Sprintf("step %d", i)), S(fmt.Sprintf("step%d", i)), Group("g"))
+	}
+	return x, out
+}
+
+func TestGroupParallel(t *testing.T) {
+	for _, parallel := range []int{1, 2, 4} {
+		t.Run(fmt.Sprint(parallel), func(t *testing.T) {
+			x, _ := groupRun(6)
+			e := &fakeExecutor{delay: 20 * time.Millisecond}
+			ctx := context.WithValue(testContext(e), CtxKeyParallel, parallel)
+			if err := x.Run(ctx); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(e.commands) != 6 {
+				t.Errorf("ran %d steps, want 6", len(e.commands))
+			}
+			if e.maxRunning != parallel {
+				t.Errorf("%d steps ran at once, want %d", e.maxRunning, parallel)
+			}
+		})
+	}
+}
+
+func TestGroupFailure(t *testing.T) {
+	tests := map[string]struct {
+		parallel int
+		delay    time.Duration
+		fail     []string
+		ran      []string
+		skipped  []int
+		failed   []int
+	}{
+		// the steps start in order, the ones after the failure are skipped
+		"sequential": {parallel: 1, fail: []string{"step2"}, ran: []string{"step1", "step2"}, skipped: []int{3, 4}, failed: []int{2}},
+		// the steps that run when a step fails complete and are reported
+		"concurrent": {parallel: 4, delay: 20 * time.Millisecond, fail: []string{"step1", "step3"}, ran: []string{"step1", "step2", "step3", "step4"}, failed: []int{1, 3}},
+		"last":       {parallel: 1, fail: []string{"step4"}, ran: []string{"step1", "step2", "step3", "step4"}, failed: []int{4}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			x, out := groupRun(4)
+			e := &fakeExecutor{delay: tc.delay, fail: map[string]int{}}
+			for _, line := range tc.fail {
+				e.fail[line] = 2
+			}
+			ctx := context.WithValue(testContext(e), CtxKeyParallel, tc.parallel)
+			err := x.Run(ctx)
+
+			ran := e.lines()
+			slices.Sort(ran)
+			if !slices.Equal(ran, tc.ran) {
+				t.Errorf("ran %v, want %v", ran, tc.ran)
+			}
+			for i := 1; i <= 4; i++ {
+				skipped := strings.Contains(out.String(), fmt.Sprintf("[%d/4] skipped, an earlier step of the group failed", i))
+				if skipped != slices.Contains(tc.skipped, i) {
+					t.Errorf("step %d skipped = %t, want %t", i, skipped, !skipped)
+				}
+			}
+			// the output of a step is prefixed with its number
+			for _, line := range ran {
+				i := strings.TrimPrefix(line, "step")
+				if !strings.Contains(out.String(), "["+i+"/4] ran "+line+"\n") {
+					t.Errorf("output of %s is not prefixed:\n%s", line, out)
+				}
+			}
+
+			steps := []int{}
+			for _, serr := range stepErrors(err) {
+				if serr.CommandExitCode != 2 {
+					t.Errorf("step %d exit code = %d, want 2", serr.Step, serr.CommandExitCode)
+				}
+				steps = append(steps, serr.Step)
+			}
+			if !slices.Equal(steps, tc.failed) {
+				t.Errorf("failed steps = %v, want %v", steps, tc.failed)
+			}
+			if got := exitcode.For(err); got != exitcode.StepFailed {
+				t.Errorf("exit code = %d, want %d", got, exitcode.StepFailed)
+			}
+		})
+	}
+}
+
+func TestGroupInterrupted(t *testing.T) {
+	x, out := groupRun(3)
+	e := &fakeExecutor{delay: time.Second}
+	ctx, cancel := context.WithCancel(testContext(e))
+	ctx = context.WithValue(ctx, CtxKeyParallel, 1)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := x.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want canceled", err)
+	}
+	if got := e.lines(); !slices.Equal(got, []string{"step1"}) {
+		t.Errorf("ran %v, want step1", got)
+	}
+	for _, i := range []int{2, 3} {
+		if !strings.Contains(out.String(), fmt.Sprintf("[%d/3] skipped, the run was interrupted", i)) {
+			t.Errorf("step %d is not reported as interrupted:\n%s", i, out)
+		}
+	}
+	if strings.Contains(out.String(), "an earlier step of the group failed") {
+		t.Errorf("the interrupted steps are reported as skipped after a failure:\n%s", out)
+	}
+}
+
+// stepErrors returns the step errors of the joined errors in order.
+func stepErrors(err error) []*exitcode.StepError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := []*exitcode.StepError{}
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, stepErrors(err)...)
+		}
+		return errs
+	}
+	var serr *exitcode.StepError
+	if errors.As(err, &serr) {
+		return []*exitcode.StepError{serr}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Hook is a function that runs at a point of a run.
+type Hook func(ctx context.Context) error
+
+// ErrorHook is a hook that gets the error of the run, nil on success.
+type ErrorHook func(ctx context.Context, err error) error
+
+// OnSetup adds a hook that runs before the steps, in the order the hooks are
+// added. An error stops the run before its steps.
+func (r *Run) OnSetup(fn Hook) {
+	r.setup = append(r.setup, fn)
+}
+
+// OnCleanup adds a hook that runs after the steps and checks. The cleanup
+// hooks always run once the setup started, also when a step failed or the
+// run was interrupted, in the reverse order they are added.
+func (r *Run) OnCleanup(fn Hook) {
+	r.cleanup = append(r.cleanup, fn)
+}
+
+// OnStepFailure adds a hook that runs when a step fails or the run is
+// interrupted during the steps, with the error of the step. It runs before
+// the cleanup hooks, e.g. to roll back what the failed step half applied.
+func (r *Run) OnStepFailure(fn ErrorHook) {
+	r.failure = append(r.failure, fn)
+}
+
+// Finally adds a hook that runs at the very end of every run, also when the
+// requirements of the run are not met, with the error of the run.
+func (r *Run) Finally(fn ErrorHook) {
+	r.finally = append(r.finally, fn)
+}
+
+// The hooks after the steps run with a context that is not canceled, so
+// they still run when the run is interrupted. Their errors are joined with
+// the error of the run.
+
+func (r *Run) runSetup(ctx context.Context) error {
+	for _, fn := range r.setup {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Run) runCleanup(ctx context.Context, err error) error {
+	ctx = context.WithoutCancel(ctx)
+	errs := []error{err}
+	for i := len(r.cleanup) - 1; i >= 0; i-- {
+		errs = append(errs, r.cleanup[i](ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Run) runStepFailure(ctx context.Context, err error) error {
+	ctx = context.WithoutCancel(ctx)
+	errs := []error{err}
+	for _, fn := range r.failure {
+		errs = append(errs, fn(ctx, err))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Run) runFinally(ctx context.Context, err error) error {
+	ctx = context.WithoutCancel(ctx)
+	errs := []error{err}
+	for _, fn := range r.finally {
+		errs = append(errs, fn(ctx, err))
+	}
+	return errors.Join(errs...)
+}
+
+// Exec runs a command from a hook with the executor and environment of the
+// run, e.g. to roll back a change of a failed step. It does not run in dry-run
+// and diff mode.
+func (r *Run) Exec(ctx context.Context, command string, opts ...StepOption) error {
+	if r.options.DryRun || r.options.Diff {
+		return nil
+	}
+	s := &step{r: r, command: []string{command}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.printCommand()
+	if err := s.execCommand(ctx, command); err != nil {
+		return fmt.Errorf("runbook %q: %q failed: %w", r.title, r.secrets.redact(command), err)
+	}
+	return nil
+}
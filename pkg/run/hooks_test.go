@@ -0,0 +1,200 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+func TestHooks(t *testing.T) {
+	errSetup := errors.New("setup failed")
+	errCleanup := errors.New("cleanup failed")
+
+	tests := map[string]struct {
+		failSetup   bool
+		failStep    bool
+		failCleanup bool
+		want        []string
+		wantErrs    []error
+		exitCode    int
+	}{
+		"success": {
+			want: []string{"setup 1", "setup 2", "run step1", "run step2", "cleanup 2", "cleanup 1", "finally <nil>"},
+		},
+		// a failed setup stops the run before its steps, the cleanup
+		// hooks still run
+		"setup failure": {
+			failSetup: true,
+			want:      []string{"setup 1", "cleanup 2", "cleanup 1", "finally setup failed"},
+			wantErrs:  []error{errSetup},
+			exitCode:  exitcode.Error,
+		},
+		// the failure hooks run before the cleanup hooks
+		"step failure": {
+			failStep: true,
+			want:     []string{"setup 1", "setup 2", "run step1", "failure step 1", "cleanup 2", "cleanup 1", "finally step 1"},
+			exitCode: exitcode.StepFailed,
+		},
+		"cleanup failure": {
+			failCleanup: true,
+			want:        []string{"setup 1", "setup 2", "run step1", "run step2", "cleanup 2", "cleanup 1", "finally cleanup failed"},
+			wantErrs:    []error{errCleanup},
+			exitCode:    exitcode.Error,
+		},
+		// the errors of the step and the hooks are joined
+		"step and cleanup failure": {
+			failStep:    true,
+			failCleanup: true,
+			want:        []string{"setup 1", "setup 2", "run step1", "failure step 1", "cleanup 2", "cleanup 1", "finally step 1"},
+			wantErrs:    []error{errCleanup},
+			exitCode:    exitcode.StepFailed,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			events := []string{}
+			e := &fakeExecutor{events: &events, fail: map[string]int{}}
+			if tc.failStep {
+				e.fail["step1"] = 1
+			}
+			x := newTestRun("Hooks", io.Discard)
+			x.Step(S("step 1"), S("step1"))
+			x.Step(S("step 2"), S("step2"))
+			x.OnSetup(func(context.Context) error {
+				events = append(events, "setup 1")
+				if tc.failSetup {
+					return errSetup
+				}
+				return nil
+			})
+			x.OnSetup(func(context.Context) error {
+				events = append(events, "setup 2")
+				return nil
+			})
+			x.OnCleanup(func(context.Context) error {
+				events = append(events, "cleanup 1")
+				if tc.failCleanup {
+					return errCleanup
+				}
+				return nil
+			})
+			x.OnCleanup(func(context.Context) error {
+				events = append(events, "cleanup 2")
+				return nil
+			})
+			x.OnStepFailure(func(_ context.Context, err error) error {
+				events = append(events, "failure "+describe(err))
+				return nil
+			})
+			x.Finally(func(_ context.Context, err error) error {
+				events = append(events, "finally "+describe(err))
+				return nil
+			})
+
+			err := x.Run(testContext(e))
+			if !slices.Equal(events, tc.want) {
+				t.Errorf("events = %q, want %q", events, tc.want)
+			}
+			for _, want := range tc.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Run() error = %v, want it to include %v", err, want)
+				}
+			}
+			if got := exitcode.For(err); got != tc.exitCode {
+				t.Errorf("exit code = %d, want %d", got, tc.exitCode)
+			}
+		})
+	}
+}
+
+// describe returns the step of a step error, else the error.
+func describe(err error) string {
+	var serr *exitcode.StepError
+	if errors.As(err, &serr) {
+		return fmt.Sprintf("step %d", serr.Step)
+	}
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+func TestHookErrorAfterInterrupt(t *testing.T) {
+	// the hooks after the steps run with a context that is not canceled
+	ctx, cancel := context.WithCancel(testContext(&fakeExecutor{}))
+	cancel()
+	x := newTestRun("Interrupted", io.Discard)
+	x.Step(S("step 1"), S("step1"))
+	var cleanupErr, finallyErr error
+	x.OnCleanup(func(ctx context.Context) error {
+		cleanupErr = ctx.Err()
+		return nil
+	})
+	x.Finally(func(ctx context.Context, _ error) error {
+		finallyErr = ctx.Err()
+		return nil
+	})
+	if err := x.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want canceled", err)
+	}
+	if cleanupErr != nil || finallyErr != nil {
+		t.Errorf("the cleanup and finally hooks ran with a canceled context: %v, %v", cleanupErr, finallyErr)
+	}
+}
+
+func TestExec(t *testing.T) {
+	tests := map[string]struct {
+		diff, dryRun bool
+		fail         bool
+		ran          bool
+		wantErr      bool
+	}{
+		"run":     {ran: true},
+		"failure": {fail: true, ran: true, wantErr: true},
+		"diff":    {diff: true},
+		"dry-run": {dryRun: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := &fakeExecutor{fail: map[string]int{}}
+			if tc.fail {
+				e.fail["rollback"] = 1
+			}
+			x := newTestRun("Exec", io.Discard)
+			x.SetEnv("LAB", "3node")
+			x.exec = e
+			x.options.Diff, x.options.DryRun = tc.diff, tc.dryRun
+			err := x.Exec(context.Background(), "rollback")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Exec() error = %v, want error %t", err, tc.wantErr)
+			}
+			if ran := len(e.commands) == 1; ran != tc.ran {
+				t.Fatalf("ran = %t, want %t", ran, tc.ran)
+			}
+			if tc.ran && !slices.Contains(e.commands[0].Env, "LAB=3node") {
+				t.Errorf("env = %v, want the env of the run", e.commands[0].Env)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+// StepInfo describes a step of a run.
+type StepInfo struct {
+	Text    []string
+	Command []string
+	Plan    string
+	Expect  string
+	// Group is the parallel group of the step, if any.
+	Group string
+	// HostOnly is true when the step always runs on the host.
+	HostOnly bool
+}
+
+// CheckInfo describes a verification check of a run.
+type CheckInfo struct {
+	Text    []string
+	Command []string
+}
+
+// Title returns the title of the run.
+func (r *Run) Title() string {
+	return r.title
+}
+
+// Description returns the description of the run.
+func (r *Run) Description() []string {
+	return r.description
+}
+
+// Steps describes the steps of the run.
+func (r *Run) Steps() []StepInfo {
+	steps := make([]StepInfo, 0, len(r.steps))
+	for _, s := range r.steps {
+		steps = append(steps, StepInfo{
+			Text:     s.text,
+			Command:  s.command,
+			Plan:     s.plan,
+			Expect:   s.expect,
+			Group:    s.group,
+			HostOnly: s.hostOnly,
+		})
+	}
+	return steps
+}
+
+// Checks describes the verification checks of the run.
+func (r *Run) Checks() []CheckInfo {
+	checks := make([]CheckInfo, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, CheckInfo{Text: c.text, Command: c.command})
+	}
+	return checks
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+)
+
+// requireTimeout bounds a single evaluation of a requirement.
+const requireTimeout = 20 * time.Second
+
+// Requirement is a prerequisite of a run: an exercise that is done first,
+// or a resource or object in the cluster that another exercise creates.
+type Requirement struct {
+	// Exercise is the exercise that provides the requirement.
+	Exercise string
+	// Text describes the resource or object, empty for an exercise.
+	Text string
+	// Command passes when the resource or object is present.
+	Command string
+}
+
+// RequireExercise requires the exercise to be done. It is met when the
+// exercise is completed in the progress or its checks pass on the cluster.
+func RequireExercise(name string) Requirement {
+	return Requirement{Exercise: name}
+}
+
+// RequireResource requires the API resource to be served, by a CRD or an
+// aggregated API, e.g. networks.network.app.kuid.dev.
+func RequireResource(resource, exercise string) Requirement {
+	return Requirement{
+		Exercise: exercise,
+		Text:     "resource " + resource,
+		Command:  fmt.Sprintf("kubectl get %s --all-namespaces -o name --request-timeout=10s", resource),
+	}
+}
+
+// RequireObject requires the object to exist.
+func RequireObject(resource, namespace, name, exercise string) Requirement {
+	return Requirement{
+		Exercise: exercise,
+		Text:     fmt.Sprintf("%s %s/%s", resource, namespace, name),
+		Command:  fmt.Sprintf("kubectl get %s %s -n %s -o name --request-timeout=10s", resource, name, namespace),
+	}
+}
+
+// String describes the requirement.
+func (r Requirement) String() string {
+	if r.Text == "" {
+		return "exercise " + r.Exercise
+	}
+	return r.Text
+}
+
+// Resolver returns the runbook of an exercise, so the requirements of a run
+// can be checked against and run the runbooks they require.
+type Resolver func(name string) (*Run, error)
+
+// SetName sets the exercise name of the run, under which its completion is
+// recorded in the progress.
+func (r *Run) SetName(name string) {
+	r.name = name
+}
+
+// Name returns the exercise name of the run.
+func (r *Run) Name() string {
+	return r.name
+}
+
+// Require adds prerequisites to the run, they are evaluated before the
+// steps in the order they are added.
+func (r *Run) Require(reqs ...Requirement) {
+	r.requires = append(r.requires, reqs...)
+}
+
+// Requirements returns the prerequisites of the run.
+func (r *Run) Requirements() []Requirement {
+	return r.requires
+}
+
+// requirements evaluates the prerequisites of the run. Unmet requirements
+// fail the run with a precondition error, or their exercise is run first
+// with --with-deps. In diff and dry-run mode they are only reported.
+func (r *Run) requirements(ctx context.Context) error {
+	if len(r.requires) == 0 {
+		return nil
+	}
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	resolve := getContextValue[Resolver](ctx, CtxKeyRunbooks)
+	withDeps := getContextValue[bool](ctx, CtxKeyWithDeps)
+	report := r.options.Diff || r.options.DryRun
+	done := map[string]bool{}
+
+	// the exercises are evaluated before the resources and objects they
+	// provide, running an exercise may provide them
+	reqs := make([]Requirement, 0, len(r.requires))
+	for _, req := range r.requires {
+		if req.Text == "" {
+			reqs = append(reqs, req)
+		}
+	}
+	for _, req := range r.requires {
+		if req.Text != "" {
+			reqs = append(reqs, req)
+		}
+	}
+
+	for _, req := range reqs {
+		if done[req.Exercise] || r.met(ctx, req, prog, resolve) {
+			continue
+		}
+		switch {
+		case report:
+			if err := write(r.out, color.Yellow.Sprintf("requirement %s is not met, run `kubenet %s` first\n", req, req.Exercise)); err != nil {
+				return err
+			}
+		case withDeps && resolve != nil:
+			if err := r.runDependency(ctx, req.Exercise, resolve); err != nil {
+				return err
+			}
+			done[req.Exercise] = true
+			// the dependency also completed the exercises it required
+			if prog, err = progress.Load(progress.File()); err != nil {
+				return err
+			}
+		default:
+			return exitcode.Preconditionf("%s requires %s: run `kubenet %s` first, or use --with-deps", r.title, req, req.Exercise)
+		}
+	}
+	return nil
+}
+
+// met returns true when the requirement is met.
+func (r *Run) met(ctx context.Context, req Requirement, prog *progress.Progress, resolve Resolver) bool {
+	if req.Text != "" {
+		return r.passes(ctx, req.Command)
+	}
+	if prog.Status(req.Exercise) == "completed" {
+		return true
+	}
+	if resolve == nil {
+		return false
+	}
+	x, err := resolve(req.Exercise)
+	if err != nil || !x.HasChecks() {
+		return false
+	}
+	for _, c := range x.checks {
+		if !r.passes(ctx, strings.Join(c.command, " ")) {
+			return false
+		}
+	}
+	return true
+}
+
+// passes runs the command once and returns true when it succeeds.
+func (r *Run) passes(ctx context.Context, command string) bool {
+	ctx, cancel := context.WithTimeout(ctx, requireTimeout)
+	defer cancel()
+	return r.exec.Execute(ctx, r.command(command, nil, ""), io.Discard, io.Discard) == nil
+}
+
+// runDependency runs the runbook of the exercise, which evaluates its own
+// requirements first.
+func (r *Run) runDependency(ctx context.Context, name string, resolve Resolver) error {
+	x, err := resolve(name)
+	if err != nil {
+		return err
+	}
+	if err := write(r.out, color.Cyan.Sprintf("%s requires exercise %s, running `kubenet %s` first\n", r.title, name, name)); err != nil {
+		return err
+	}
+	// the recording is of the requested run only
+	ctx = context.WithValue(ctx, CtxKeyRecord, "")
+	if err := x.Run(ctx); err != nil {
+		return fmt.Errorf("prerequisite %s: %w", name, err)
+	}
+	return write(r.out, "\n")
+}
+
+// complete records the exercise of the run as completed.
+func (r *Run) complete() error {
+	if r.name == "" || r.options.Diff || r.options.DryRun {
+		return nil
+	}
+	prog, err := progress.Load(progress.File())
+	if err != nil {
+		return err
+	}
+	prog.Get(r.name).Complete()
+	return prog.Save()
+}
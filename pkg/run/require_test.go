@@ -0,0 +1,161 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+)
+
+// stateDir points the progress to a temporary state directory.
+func stateDir(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+}
+
+// exercises are the runbooks of the requirement tests: network requires
+// setup, each runs a step named after it and checks <name>-ready.
+func exercises(resolved *[]string) Resolver {
+	return func(name string) (*Run, error) {
+		*resolved = append(*resolved, name)
+		x := newTestRun(name, io.Discard)
+		x.SetName(name)
+		switch name {
+		case "setup":
+		case "network":
+			x.Require(RequireExercise("setup"))
+		default:
+			return nil, fmt.Errorf("unknown exercise %s", name)
+		}
+		x.Step(S("run "+name), S(name))
+		x.Check(S(name+" is ready"), S(name+"-ready"))
+		return x, nil
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	resource := RequireResource("networks.network.app.kuid.dev", "network")
+
+	tests := map[string]struct {
+		reqs      []Requirement
+		completed []string
+		fail      []string
+		withDeps  bool
+		diff      bool
+		// ran are the commands of the steps, not the evaluations
+		ran      []string
+		hint     string
+		exitCode int
+	}{
+		"missing exercise": {
+			reqs:     []Requirement{RequireExercise("setup")},
+			fail:     []string{"setup-ready"},
+			hint:     "requires exercise setup: run `kubenet setup` first, or use --with-deps",
+			exitCode: exitcode.Precondition,
+		},
+		"missing resource": {
+			reqs:     []Requirement{resource},
+			fail:     []string{resource.Command},
+			hint:     "requires resource networks.network.app.kuid.dev: run `kubenet network` first",
+			exitCode: exitcode.Precondition,
+		},
+		"completed exercise": {
+			reqs:      []Requirement{RequireExercise("setup")},
+			completed: []string{"setup"},
+			fail:      []string{"setup-ready"},
+			ran:       []string{"main"},
+		},
+		// an exercise that is not recorded is met when its checks pass
+		"checks pass": {
+			reqs: []Requirement{RequireExercise("setup")},
+			ran:  []string{"main"},
+		},
+		// in diff mode the unmet requirements are reported
+		"diff": {
+			reqs: []Requirement{RequireExercise("setup")},
+			fail: []string{"setup-ready"},
+			diff: true,
+			hint: "requirement exercise setup is not met, run `kubenet setup` first",
+		},
+		// the dependencies run once and in order, before the steps
+		"with deps": {
+			reqs:     []Requirement{resource, RequireExercise("network"), RequireExercise("setup")},
+			fail:     []string{"setup-ready", "network-ready", resource.Command},
+			withDeps: true,
+			ran:      []string{"setup", "network", "main"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stateDir(t)
+			prog, err := progress.Load(progress.File())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tc.completed {
+				prog.Get(name).Complete()
+			}
+			if err := prog.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			e := &fakeExecutor{fail: map[string]int{}}
+			for _, line := range tc.fail {
+				e.fail[line] = 1
+			}
+			resolved := []string{}
+			ctx := context.WithValue(testContext(e), CtxKeyRunbooks, exercises(&resolved))
+			ctx = context.WithValue(ctx, CtxKeyWithDeps, tc.withDeps)
+			ctx = context.WithValue(ctx, CtxKeyDiff, tc.diff)
+			// the checks of the dependencies are skipped, they fail
+			ctx = context.WithValue(ctx, CtxKeySkipVerify, true)
+
+			out := &strings.Builder{}
+			x := newTestRun("Main", out)
+			x.Require(tc.reqs...)
+			x.Step(S("run main"), S("main"))
+			err = x.Run(ctx)
+
+			if got := exitcode.For(err); got != tc.exitCode {
+				t.Fatalf("Run() error = %v, exit code %d, want %d", err, got, tc.exitCode)
+			}
+			hint := out.String()
+			if err != nil {
+				hint = err.Error()
+			}
+			if !strings.Contains(hint, tc.hint) {
+				t.Errorf("hint = %q, want %q", hint, tc.hint)
+			}
+			ran := slices.DeleteFunc(e.lines(), func(line string) bool {
+				return strings.HasSuffix(line, "-ready") || strings.HasPrefix(line, "kubectl get")
+			})
+			if !slices.Equal(ran, tc.ran) {
+				t.Errorf("ran %v, want %v", ran, tc.ran)
+			}
+		})
+	}
+}
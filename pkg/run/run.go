@@ -21,19 +21,33 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"time"
 
 	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/pkg/cast"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
 )
 
 type Run struct {
+	name        string
 	title       string
 	description []string
 	steps       []step
+	checks      []check
+	requires    []Requirement
+	undo        []step
 	out         io.Writer
-	setup       func() error
-	cleanup     func() error
+	setup       []Hook
+	cleanup     []Hook
+	failure     []ErrorHook
+	finally     []ErrorHook
 	options     *Options
+	exec        Executor
+	env         []envVar
+	dir         string
+	allow       []string
+	secrets     secrets
 }
 
 // Options specify the run options.
@@ -44,14 +58,15 @@ type Options struct {
 	ContinueOnError  bool
 	HideDescriptions bool
 	DryRun           bool
+	Diff             bool
 	NoColor          bool
 	Immediate        bool
 	SkipSteps        int
 	Shell            string
+	// Parallel limits the steps of a group that run concurrently.
+	Parallel int
 }
 
-func emptyFn() error { return nil }
-
 // NewRun creates a new run for the provided description string.
 func NewRun(title string, description ...string) *Run {
 	return &Run{
@@ -59,25 +74,77 @@ func NewRun(title string, description ...string) *Run {
 		description: description,
 		steps:       nil,
 		out:         os.Stdout,
-		setup:       emptyFn,
-		cleanup:     emptyFn,
 		options:     &Options{},
 	}
 }
 
-func (r *Run) Step(text, command []string) {
-	r.steps = append(r.steps, step{r, text, command, false, false})
+func (r *Run) Step(text, command []string, opts ...StepOption) {
+	s := step{r: r, text: text, command: command}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	r.steps = append(r.steps, s)
 }
 
-func (r *Run) Run(ctx context.Context) error {
+func (r *Run) Run(ctx context.Context) (err error) {
+	defer func() {
+		if err == nil {
+			if cerr := r.complete(); cerr != nil {
+				err = fmt.Errorf("cannot record the progress of %s: %w", r.name, cerr)
+			}
+		}
+		err = r.runFinally(ctx, err)
+	}()
+
+	if shell := getContextValue[string](ctx, CtxKeyShell); shell != "" {
+		r.options.Shell = shell
+	}
 	if r.options.Shell == "" {
 		r.options.Shell = "bash"
 	}
+	r.exec = executor(ctx)
+	if _, local := r.exec.(Local); local {
+		if _, err := exec.LookPath(r.options.Shell); err != nil {
+			return exitcode.Preconditionf("shell %s not found: %w", r.options.Shell, err)
+		}
+	}
+	r.options.Diff = getContextValue[bool](ctx, CtxKeyDiff)
+	r.options.Parallel = DefaultParallel
+	if parallel := getContextValue[int](ctx, CtxKeyParallel); parallel > 0 {
+		r.options.Parallel = parallel
+	}
+	r.options.AutoTimeout = getContextValue[time.Duration](ctx, CtxKeyStepDelay)
+	r.options.NoColor = !color.Enable
+
+	if allow := getContextValue[[]string](ctx, CtxKeyEnvAllowlist); len(allow) > 0 {
+		r.AllowEnv(allow...)
+	}
+	r.registerSecrets()
 
 	//r.options.Auto = getContextValue[bool](ctx, CtxKeyAutomatic)
 	r.options.Auto = true // always run in automatic mode
 
-	if err := r.setup(); err != nil {
+	if err := r.requirements(ctx); err != nil {
+		return err
+	}
+
+	if record := getContextValue[string](ctx, CtxKeyRecord); record != "" {
+		f, err := os.Create(record)
+		if err != nil {
+			return fmt.Errorf("cannot create recording: %w", err)
+		}
+		defer f.Close()
+		// the index only serves completion, a recording without it is fine
+		_ = cast.Remember(cast.IndexFile(), record)
+		w, err := cast.NewWriter(f, cast.NewHeader(r.title))
+		if err != nil {
+			return err
+		}
+		r.out = io.MultiWriter(r.out, w)
+	}
+
+	defer func() { err = r.runCleanup(ctx, err) }()
+	if err := r.runSetup(ctx); err != nil {
 		return err
 	}
 
@@ -85,20 +152,48 @@ func (r *Run) Run(ctx context.Context) error {
 		return err
 	}
 
-	for i, step := range r.steps {
+	if err := r.runSteps(ctx); err != nil {
+		return r.runStepFailure(ctx, err)
+	}
 
-		if err := step.run(i+1, len(r.steps)); err != nil {
+	if r.HasChecks() && !r.options.Diff && !r.options.DryRun && !getContextValue[bool](ctx, CtxKeySkipVerify) {
+		timeout := getContextValue[time.Duration](ctx, CtxKeyVerifyTimeout)
+		if err := r.verify(ctx, timeout); err != nil {
 			return err
 		}
-
 	}
 
-	return r.cleanup()
+	return nil
+}
+
+// runSteps runs the steps in order, the adjacent steps of a group
+// concurrently.
+func (r *Run) runSteps(ctx context.Context) error {
+	for i := 0; i < len(r.steps); i++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("runbook %q stopped before step %d/%d: %w", r.title, i+1, len(r.steps), err)
+		}
+		if group := r.steps[i].group; group != "" {
+			j := i + 1
+			for j < len(r.steps) && r.steps[j].group == group {
+				j++
+			}
+			if err := r.runGroup(ctx, i+1, r.steps[i:j]); err != nil {
+				return err
+			}
+			i = j - 1
+			continue
+		}
+		if err := r.steps[i].run(ctx, i+1, len(r.steps)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *Run) printTitleAndDescription() error {
 	p := color.Cyan.Sprintf
-	if err := write(r.out, p("%s\n", r.title)); err != nil {
+	if err := write(r.out, p("%s\n", r.secrets.redact(r.title))); err != nil {
 		return err
 	}
 	for range r.title {
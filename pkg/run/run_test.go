@@ -0,0 +1,113 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+func TestMain(m *testing.M) {
+	// the output is compared without the colors
+	color.Disable()
+	os.Exit(m.Run())
+}
+
+// fakeExecutor records the commands instead of running them.
+type fakeExecutor struct {
+	// fail are the exit codes of the command lines that fail
+	fail map[string]int
+	// delay is how long a command runs
+	delay time.Duration
+	// events records "run <line>" per command when set, e.g. next to the
+	// calls of the hooks
+	events *[]string
+
+	mu         sync.Mutex
+	commands   []Command
+	running    int
+	maxRunning int
+}
+
+func (r *fakeExecutor) Execute(ctx context.Context, cmd Command, stdout, _ io.Writer) error {
+	r.mu.Lock()
+	r.commands = append(r.commands, cmd)
+	if r.events != nil {
+		*r.events = append(*r.events, "run "+cmd.Line)
+	}
+	r.running++
+	r.maxRunning = max(r.maxRunning, r.running)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	fmt.Fprintf(stdout, "ran %s\n", cmd.Line)
+	if code, ok := r.fail[cmd.Line]; ok {
+		return exitStatus(code)
+	}
+	return nil
+}
+
+// lines returns the command lines in the order they ran.
+func (r *fakeExecutor) lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := []string{}
+	for _, c := range r.commands {
+		lines = append(lines, c.Line)
+	}
+	return lines
+}
+
+// exitStatus is the error of a command that exits with a code.
+type exitStatus int
+
+func (r exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(r))
+}
+
+func (r exitStatus) ExitStatus() int {
+	return int(r)
+}
+
+// newTestRun returns a run that prints immediately to out.
+func newTestRun(title string, out io.Writer) *Run {
+	x := NewRun(title)
+	x.out = out
+	x.options.Immediate = true
+	return x
+}
+
+// testContext returns a context that runs the commands with the executor.
+func testContext(e Executor) context.Context {
+	return context.WithValue(context.Background(), CtxKeyExecutor, e)
+}
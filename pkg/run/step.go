@@ -18,23 +18,86 @@ package run
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/gookit/color"
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
 )
 
 type step struct {
 	r                     *Run
 	text, command         []string
 	canFail, isBreakPoint bool
+	plan                  string
+	expect                string
+	group                 string
+	hostOnly              bool
+	env                   []envVar
+	dir                   string
+	timeout               time.Duration
+	// out replaces the output of the run while the step runs in a group
+	out io.Writer
 }
 
-func (s *step) run(current, max int) error {
+// StepOption configures a step.
+type StepOption func(*step)
+
+// Plan describes the change a host step makes, e.g. "would create kind
+// cluster kubenet". It is shown instead of running the step in diff mode.
+func Plan(format string, a ...any) StepOption {
+	return func(s *step) {
+		s.plan = fmt.Sprintf(format, a...)
+	}
+}
+
+// Group runs the step concurrently with the adjacent steps of the same
+// group. The group completes when all its steps complete.
+func Group(name string) StepOption {
+	return func(s *step) {
+		s.group = name
+	}
+}
+
+// HostOnly runs the step on the host also when the other steps run in a
+// container, e.g. a change of the iptables rules of the host.
+func HostOnly() StepOption {
+	return func(s *step) {
+		s.hostOnly = true
+	}
+}
+
+// Timeout bounds the command of the step, it is stopped when it does not
+// complete in time and the run fails with a timeout.
+func Timeout(d time.Duration) StepOption {
+	return func(s *step) {
+		s.timeout = d
+	}
+}
+
+// executor returns the executor of the step.
+func (s *step) executor() Executor {
+	if h, ok := s.r.exec.(HostExecutor); ok && s.hostOnly {
+		return h.Host()
+	}
+	return s.r.exec
+}
+
+// Expect describes the expected outcome of a step, used in the rendered
+// documentation of the runbook.
+func Expect(format string, a ...any) StepOption {
+	return func(s *step) {
+		s.expect = fmt.Sprintf(format, a...)
+	}
+}
+
+func (s *step) run(ctx context.Context, current, max int) error {
 	if err := s.waitOrSleep(); err != nil {
 		return fmt.Errorf("unable to run step: %v: %w", s, err)
 	}
@@ -45,7 +108,7 @@ func (s *step) run(current, max int) error {
 		return s.wait()
 	}
 	if len(s.command) > 0 {
-		return s.execute()
+		return s.execute(ctx, current, max)
 	}
 
 	return nil
@@ -121,18 +184,27 @@ func (s *step) echo(current, max int) {
 	s.print(prepared...)
 }
 
+func (s *step) writer() io.Writer {
+	if s.out != nil {
+		return s.out
+	}
+	return s.r.out
+}
+
 func (s *step) print(msg ...string) error {
 	for _, m := range msg {
+		m = s.r.secrets.redact(m)
 		for _, c := range m {
-			if !s.r.options.Immediate {
+			// the output of concurrent steps is not typed
+			if !s.r.options.Immediate && s.out == nil {
 				//nolint:gosec,gomnd // the sleep has no security implications and is randomly chosen
 				time.Sleep(time.Duration(rand.Intn(40)) * time.Millisecond)
 			}
-			if err := write(s.r.out, fmt.Sprintf("%c", c)); err != nil {
+			if err := write(s.writer(), fmt.Sprintf("%c", c)); err != nil {
 				return err
 			}
 		}
-		if err := write(s.r.out, "\n"); err != nil {
+		if err := write(s.writer(), "\n"); err != nil {
 			return err
 		}
 	}
@@ -140,13 +212,15 @@ func (s *step) print(msg ...string) error {
 	return nil
 }
 
-func (s *step) execute() error {
-	joinedCommand := strings.Join(s.command, " ")
-	cmd := exec.Command(s.r.options.Shell, "-c", joinedCommand) //nolint:gosec // we purposefully run user-provided code
-
-	cmd.Stderr = s.r.out
-	cmd.Stdout = s.r.out
+func (s *step) execute(ctx context.Context, current, max int) error {
+	s.printCommand()
+	if err := s.waitOrSleep(); err != nil {
+		return fmt.Errorf("unable to execute step: %v: %w", s, err)
+	}
+	return s.runCommand(ctx, current, max)
+}
 
+func (s *step) printCommand() {
 	p := color.Green.Sprintf
 	if s.r.options.NoColor {
 		p = fmt.Sprintf
@@ -154,21 +228,102 @@ func (s *step) execute() error {
 
 	cmdString := p("> %s", strings.Join(s.command, " \\\n    "))
 	s.print(cmdString)
-	if err := s.waitOrSleep(); err != nil {
-		return fmt.Errorf("unable to execute step: %v: %w", s, err)
-	}
+}
+
+func (s *step) runCommand(ctx context.Context, current, max int) error {
+	joinedCommand := strings.Join(s.command, " ")
 	if s.r.options.DryRun {
 		return nil
 	}
-	err := cmd.Run()
+	if s.r.options.Diff {
+		return s.diff(ctx, current, max)
+	}
+	cmdCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	err := s.execCommand(cmdCtx, joinedCommand)
 	if s.canFail {
 		return nil
 	}
-	s.print("")
+	if s.out == nil {
+		s.print("")
+	}
 
+	if err != nil && ctx.Err() == nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		return &exitcode.TimeoutError{
+			Op:      fmt.Sprintf("runbook %q step %d/%d %q", s.r.title, current, max, s.r.secrets.redact(strings.Join(s.text, " "))),
+			Timeout: s.timeout,
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("step command failed: %w", err)
+		return s.error(ctx, current, max, joinedCommand, err)
 	}
 
 	return nil
 }
+
+// execCommand runs the command line in the environment of the step, the
+// secrets are redacted from its output.
+func (s *step) execCommand(ctx context.Context, line string) error {
+	w := &redactWriter{secrets: &s.r.secrets, out: s.writer()}
+	err := s.executor().Execute(ctx, s.r.command(line, s.env, s.dir), w, w)
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// error returns the error of the failed step command, or the context error
+// when the command was interrupted or timed out.
+func (s *step) error(ctx context.Context, current, max int, command string, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("runbook %q stopped at step %d/%d: %w", s.r.title, current, max, ctx.Err())
+	}
+	return &exitcode.StepError{
+		Runbook:         s.r.title,
+		Step:            current,
+		Steps:           max,
+		Text:            s.r.secrets.redact(strings.Join(s.text, " ")),
+		Command:         s.r.secrets.redact(command),
+		CommandExitCode: exitCode(err),
+		Err:             err,
+	}
+}
+
+const kubectlApply = "kubectl apply "
+
+// diff shows what the step would change. Manifests are diffed against the
+// live cluster with a server-side dry-run, other steps print their plan.
+func (s *step) diff(ctx context.Context, current, max int) error {
+	p := color.Yellow.Sprintf
+	if s.r.options.NoColor {
+		p = fmt.Sprintf
+	}
+
+	joinedCommand := strings.Join(s.command, " ")
+	if !strings.HasPrefix(joinedCommand, kubectlApply) {
+		plan := s.plan
+		if plan == "" {
+			plan = fmt.Sprintf("would run: %s", joinedCommand)
+		}
+		return s.print(p("~ %s", plan), "")
+	}
+
+	diffCommand := "kubectl diff --server-side " + strings.TrimPrefix(joinedCommand, kubectlApply)
+	err := s.execCommand(ctx, diffCommand)
+	if s.out == nil {
+		s.print("")
+	}
+
+	// kubectl diff exits with 1 when there are differences
+	if exitCode(err) == 1 {
+		return nil
+	}
+	if err != nil {
+		return s.error(ctx, current, max, diffCommand, err)
+	}
+	return s.print(p("~ no changes"), "")
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/progress"
+)
+
+// Cleanup adds an explicit cleanup step to the run, it undoes a step that
+// is not undone by deleting a manifest, e.g. a change on the host.
+func (r *Run) Cleanup(text, command []string, opts ...StepOption) {
+	s := step{text: text, command: command}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	r.undo = append(r.undo, s)
+}
+
+// Undo returns the run that reverts the run: the cleanup steps, then for
+// the apply steps in reverse order a delete of the same manifests that
+// waits for the finalizers of the objects. The undo runs with the
+// environment, working directory, secrets and setup and cleanup hooks of the
+// run, e.g. the hooks that write the manifests it applies. The exercise of
+// the run is no longer completed after the undo.
+func (r *Run) Undo() *Run {
+	x := NewRun("Undo "+r.title, "Reverts "+r.title+".")
+	options := *r.options
+	x.options = &options
+	x.out = r.out
+	x.env = slices.Clone(r.env)
+	x.dir = r.dir
+	x.allow = slices.Clone(r.allow)
+	x.secrets.add(r.secrets.values...)
+	x.setup = slices.Clone(r.setup)
+	x.cleanup = slices.Clone(r.cleanup)
+	for _, s := range r.undo {
+		s.r = x
+		x.steps = append(x.steps, s)
+	}
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		s := r.steps[i]
+		cmd := strings.Join(s.command, " ")
+		if !strings.HasPrefix(cmd, kubectlApply) {
+			continue
+		}
+		args := deleteArgs(strings.TrimPrefix(cmd, kubectlApply))
+		// the delete keeps the environment, directory and executor of the
+		// apply
+		s.r = x
+		s.text = S("undo: " + strings.Join(s.text, " "))
+		s.command = S("kubectl delete " + args + " --ignore-not-found --wait")
+		s.plan = "would delete the objects of " + strings.TrimPrefix(args, "-f ")
+		s.expect = ""
+		x.steps = append(x.steps, s)
+	}
+	x.Finally(func(_ context.Context, err error) error {
+		if err != nil || r.name == "" || x.options.Diff || x.options.DryRun {
+			return nil
+		}
+		prog, err := progress.Load(progress.File())
+		if err != nil {
+			return err
+		}
+		if e, ok := prog.Exercises[r.name]; ok {
+			e.Completed = nil
+		}
+		return prog.Save()
+	})
+	return x
+}
+
+// applyFlags are the flags of kubectl apply that kubectl delete does not
+// take.
+var applyFlags = []string{"--server-side", "--force-conflicts", "--field-manager", "--overwrite", "--prune"}
+
+// deleteArgs returns the arguments of kubectl apply without its own flags,
+// the flags with a value are expected in the --flag=value form.
+func deleteArgs(args string) string {
+	fields := strings.Fields(args)
+	return strings.Join(slices.DeleteFunc(fields, func(f string) bool {
+		name, _, _ := strings.Cut(f, "=")
+		return slices.Contains(applyFlags, name)
+	}), " ")
+}
+
+// HasSteps returns true when the run has steps.
+func (r *Run) HasSteps() bool {
+	return len(r.steps) > 0
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	x := newTestRun("Deploy", io.Discard)
+	x.Step(S("create the cluster"), S("kind create cluster"))
+	x.Step(S("apply the crds"), S("kubectl apply --server-side --force-conflicts --field-manager=kubenet -f crds.yaml"))
+	x.Step(S("apply the network"), S("kubectl apply", "-f network.yaml -n lab"), Group("apply"), Env("NS", "lab"), WorkDir("/lab"), HostOnly())
+	x.Step(S("show the network"), S("kubectl get networks"))
+	x.Cleanup(S("delete the cluster"), S("kind delete cluster"), HostOnly())
+
+	u := x.Undo()
+	if got, want := u.Title(), "Undo Deploy"; got != want {
+		t.Errorf("Title() = %q, want %q", got, want)
+	}
+	// the cleanup steps, then a delete per apply in reverse order
+	want := []string{
+		"kind delete cluster",
+		"kubectl delete -f network.yaml -n lab --ignore-not-found --wait",
+		"kubectl delete -f crds.yaml --ignore-not-found --wait",
+	}
+	got := []string{}
+	for _, s := range u.Steps() {
+		got = append(got, strings.Join(s.Command, " "))
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Undo() steps = %q, want %q", got, want)
+	}
+
+	steps := u.Steps()
+	if !steps[0].HostOnly {
+		t.Error("the cleanup step is not host only")
+	}
+	del := steps[1]
+	if got := strings.Join(del.Text, " "); got != "undo: apply the network" {
+		t.Errorf("delete text = %q", got)
+	}
+	if del.Plan != "would delete the objects of network.yaml -n lab" {
+		t.Errorf("delete plan = %q", del.Plan)
+	}
+	if del.Group != "apply" || !del.HostOnly {
+		t.Errorf("delete group = %q, host only = %t, want the ones of the apply", del.Group, del.HostOnly)
+	}
+	if cmd := u.command("", u.steps[1].env, u.steps[1].dir); !slices.Contains(cmd.Env, "NS=lab") || cmd.Dir != "/lab" {
+		t.Errorf("delete env = %v, dir = %q, want the ones of the apply", cmd.Env, cmd.Dir)
+	}
+}
+
+func TestDeleteArgs(t *testing.T) {
+	tests := map[string]string{
+		"-f network.yaml":                                   "-f network.yaml",
+		"--kustomize lab/":                                  "--kustomize lab/",
+		"-f crds.yaml  -n lab":                              "-f crds.yaml -n lab",
+		"--server-side -f crds.yaml":                        "-f crds.yaml",
+		"--server-side=true --force-conflicts -f crds.yaml": "-f crds.yaml",
+		"--field-manager=kubenet --prune -l app=x -f lab/":  "-l app=x -f lab/",
+		"-f lab/ --overwrite":                               "-f lab/",
+	}
+	for args, want := range tests {
+		if got := deleteArgs(args); got != want {
+			t.Errorf("deleteArgs(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+func TestUndoKeepsRunSettings(t *testing.T) {
+	out := &strings.Builder{}
+	x := newTestRun("Configure", out)
+	x.SetEnv("LAB", "3node")
+	x.SetSecretEnv("PASSWORD", "s3cr3t")
+	x.SetWorkDir("/lab")
+	x.AllowEnv("LAB_TOKEN")
+	x.Redact("t0ken")
+	calls := []string{}
+	x.OnSetup(func(context.Context) error {
+		calls = append(calls, "write manifest")
+		return nil
+	})
+	x.OnCleanup(func(context.Context) error {
+		calls = append(calls, "remove manifest")
+		return nil
+	})
+	x.Step(S("apply the manifest"), S("kubectl apply -f /tmp/kubenet-1.yaml"))
+
+	e := &fakeExecutor{}
+	if err := x.Undo().Run(testContext(e)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"write manifest", "remove manifest"}; !slices.Equal(calls, want) {
+		t.Errorf("hooks = %v, want %v", calls, want)
+	}
+	if len(e.commands) != 1 {
+		t.Fatalf("commands = %v, want the delete", e.lines())
+	}
+	cmd := e.commands[0]
+	if cmd.Line != "kubectl delete -f /tmp/kubenet-1.yaml --ignore-not-found --wait" {
+		t.Errorf("command = %q", cmd.Line)
+	}
+	if !slices.Equal(cmd.Env, []string{"LAB=3node", "PASSWORD=s3cr3t"}) || cmd.Dir != "/lab" || !slices.Contains(cmd.Allow, "LAB_TOKEN") {
+		t.Errorf("command env = %v, dir = %q, allow = %v, want the ones of the run", cmd.Env, cmd.Dir, cmd.Allow)
+	}
+	if got := x.Undo().secrets.redact("t0ken"); got != Redacted {
+		t.Errorf("the secrets of the run are not redacted in the undo: %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secret reads credentials from the sources a student can keep
+// them in, so they never end up in the lab material or a command line.
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/prompt"
+)
+
+// Sources of a secret, the value of a source follows its prefix.
+const (
+	// SourcePrompt asks for the secret on the terminal, twice.
+	SourcePrompt = "prompt"
+	// SourceEnv reads the environment variable, e.g. env:SRL_PASSWORD.
+	SourceEnv = "env:"
+	// SourceFile reads the file, e.g. file:~/.srl-password.
+	SourceFile = "file:"
+	// SourceExec runs the command of a password manager and reads the first
+	// line of its output, e.g. exec:pass show lab/srl.
+	SourceExec = "exec:"
+)
+
+// Sources documents the sources of a secret.
+const Sources = "prompt, env:NAME, file:PATH or exec:COMMAND"
+
+// Read reads the secret from the source. The exec command runs with the
+// shell, its stdin and stderr are the ones of kubenet so it can ask for a
+// passphrase.
+func Read(ctx context.Context, source, shell, name string) (string, error) {
+	var value string
+	switch {
+	case source == SourcePrompt:
+		v, err := prompt.Password(os.Stdin, os.Stderr, name)
+		if err != nil {
+			return "", err
+		}
+		again, err := prompt.Password(os.Stdin, os.Stderr, name+" (again)")
+		if err != nil {
+			return "", err
+		}
+		if v != again {
+			return "", fmt.Errorf("the %s entries do not match", name)
+		}
+		value = v
+	case strings.HasPrefix(source, SourceEnv):
+		env := strings.TrimPrefix(source, SourceEnv)
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("%s: environment variable %s is not set", name, env)
+		}
+		value = v
+	case strings.HasPrefix(source, SourceFile):
+		path := strings.TrimPrefix(source, SourceFile)
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			path = home + "/" + rest
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		value = strings.TrimRight(string(b), "\r\n")
+	case strings.HasPrefix(source, SourceExec):
+		command := strings.TrimPrefix(source, SourceExec)
+		cmd := exec.CommandContext(ctx, shell, "-c", command) //nolint:gosec // the command is provided by the user
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: command %q failed: %w", name, command, err)
+		}
+		line, _, _ := bytes.Cut(out, []byte("\n"))
+		value = strings.TrimRight(string(line), "\r")
+	default:
+		return "", fmt.Errorf("%s: invalid source %q, expected one of %s", name, source, Sources)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", name)
+	}
+	return value, nil
+}
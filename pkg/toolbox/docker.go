@@ -0,0 +1,217 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toolbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+)
+
+// DefaultSocket is the socket of the local docker daemon.
+const DefaultSocket = "/var/run/docker.sock"
+
+// ErrNoImage is returned when a container is created from an image that is
+// not pulled yet.
+var ErrNoImage = errors.New("no such image")
+
+// Docker is a client of the docker engine API on a unix socket.
+type Docker struct {
+	Socket string
+	Client *http.Client
+}
+
+// NewDocker returns a client of the daemon of DOCKER_HOST, the local daemon
+// when it is empty. Only unix sockets are supported.
+func NewDocker(host string) (*Docker, error) {
+	socket := DefaultSocket
+	if host != "" {
+		if !strings.HasPrefix(host, "unix://") {
+			return nil, exitcode.Preconditionf("DOCKER_HOST %s is not supported by the toolbox, it uses the local docker daemon", host)
+		}
+		socket = strings.TrimPrefix(host, "unix://")
+	}
+	return &Docker{
+		Socket: socket,
+		Client: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}},
+	}, nil
+}
+
+// ContainerSpec is the body of a container create request.
+type ContainerSpec struct {
+	Image      string
+	Cmd        []string
+	Env        []string
+	WorkingDir string
+	HostConfig HostConfig
+}
+
+// HostConfig is the host configuration of a container.
+type HostConfig struct {
+	Binds       []string
+	NetworkMode string
+	PidMode     string
+	Privileged  bool
+}
+
+// Create creates a container and returns its id.
+func (r *Docker) Create(ctx context.Context, spec ContainerSpec) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	resp, err := r.do(ctx, http.MethodPost, "/containers/create", nil, spec)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w %s", ErrNoImage, spec.Image)
+	}
+	if err := decode(resp, &created); err != nil {
+		return "", fmt.Errorf("create toolbox container: %w", err)
+	}
+	return created.ID, nil
+}
+
+// Start starts the container.
+func (r *Docker) Start(ctx context.Context, id string) error {
+	return r.call(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil)
+}
+
+// Logs follows the output of the container until it exits.
+func (r *Docker) Logs(ctx context.Context, id string, stdout, stderr io.Writer) error {
+	q := url.Values{"follow": {"1"}, "stdout": {"1"}, "stderr": {"1"}}
+	resp, err := r.do(ctx, http.MethodGet, "/containers/"+id+"/logs", q, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := decode(resp, nil); err != nil {
+		return err
+	}
+	return demux(resp.Body, stdout, stderr)
+}
+
+// Wait waits for the container to exit and returns its exit code.
+func (r *Docker) Wait(ctx context.Context, id string) (int, error) {
+	var result struct{ StatusCode int }
+	resp, err := r.do(ctx, http.MethodPost, "/containers/"+id+"/wait", nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := decode(resp, &result); err != nil {
+		return 0, err
+	}
+	return result.StatusCode, nil
+}
+
+// Remove removes the container, it is killed when it still runs.
+func (r *Docker) Remove(ctx context.Context, id string) error {
+	return r.call(ctx, http.MethodDelete, "/containers/"+id, url.Values{"force": {"1"}}, nil)
+}
+
+// Pull pulls the image.
+func (r *Docker) Pull(ctx context.Context, image string) error {
+	name, tag := imageRef(image)
+	resp, err := r.do(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {name}, "tag": {tag}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := decode(resp, nil); err != nil {
+		return &exitcode.DownloadError{URL: image, Err: err}
+	}
+	// the progress of the pull is streamed, a failure is reported in it
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct{ Error string }
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return &exitcode.DownloadError{URL: image, Err: err}
+		}
+		if msg.Error != "" {
+			return &exitcode.DownloadError{URL: image, Err: errors.New(msg.Error)}
+		}
+	}
+}
+
+func (r *Docker) call(ctx context.Context, method, path string, q url.Values, body any) error {
+	resp, err := r.do(ctx, method, path, q, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decode(resp, nil)
+}
+
+func (r *Docker) do(ctx context.Context, method, path string, q url.Values, body any) (*http.Response, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	u := "http://docker" + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker %s %s: %w", method, path, err)
+	}
+	return resp, nil
+}
+
+// decode checks the status of the response and decodes its body into v.
+func decode(resp *http.Response, v any) error {
+	if resp.StatusCode >= 300 {
+		var msg struct{ Message string }
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil || msg.Message == "" {
+			return fmt.Errorf("docker: %s", resp.Status)
+		}
+		return fmt.Errorf("docker: %s", msg.Message)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package toolbox runs the commands of the runbooks in containers of the
+// kubenet toolbox image, which ships the versions of kind, kubectl and
+// containerlab the lab material is tested with.
+package toolbox
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/release"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+)
+
+// DefaultImage is the toolbox image published with each release, built
+// from Dockerfile.toolbox.
+const DefaultImage = "ghcr.io/kubenet-dev/kubenetctl-toolbox"
+
+// Image returns the toolbox image of the release version, the latest image
+// for a build that is not released.
+func Image(version string) string {
+	if version == "" || version == "0.0.0" {
+		return DefaultImage + ":latest"
+	}
+	return DefaultImage + ":" + release.Tag(version)
+}
+
+// hostPaths are mounted into the toolbox when they exist, containerlab
+// manages the network namespaces and the hosts file of the lab nodes.
+var hostPaths = []string{"/var/run/netns", "/var/lib/docker/containers", "/etc/hosts"}
+
+// fileFlags are the kubectl flags that take a local file or directory.
+var fileFlags = map[string]bool{"-f": true, "--filename": true, "-k": true, "--kustomize": true}
+
+// Toolbox runs each command in a new container of the image through the
+// API of the local docker daemon. The containers share the network and the
+// process namespace of the host and have the docker socket, the kubeconfig,
+// the temporary directory, the working directory and the local files the
+// command applies mounted at the same paths.
+type Toolbox struct {
+	Image  string
+	Docker *Docker
+	Binds  []string
+	Env    []string
+	// WorkDir is the working directory of the commands.
+	WorkDir string
+
+	once   sync.Once
+	err    error
+	mu     sync.Mutex
+	pulled bool
+}
+
+// New returns a toolbox of the image that uses the docker daemon of
+// DOCKER_HOST and the kubeconfig of KUBECONFIG. They are set up on the first
+// command, commands that run no runbook do not need docker.
+func New(image string) *Toolbox {
+	return &Toolbox{Image: image}
+}
+
+// setup sets up the toolbox once.
+func (r *Toolbox) setup() error {
+	r.once.Do(func() {
+		r.err = r.init()
+	})
+	return r.err
+}
+
+func (r *Toolbox) init() error {
+	if r.Image == "" {
+		return exitcode.Preconditionf("no toolbox image, set toolbox-image")
+	}
+	if r.Docker != nil {
+		return nil
+	}
+	d, err := NewDocker(os.Getenv("DOCKER_HOST"))
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	kubeconfig, err := kubeconfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(kubeconfig), 0700); err != nil {
+		return err
+	}
+	binds := []string{d.Socket + ":/var/run/docker.sock"}
+	// the generated manifests are written to the temporary directory
+	for _, p := range []string{filepath.Dir(kubeconfig), os.TempDir(), wd} {
+		binds = addBind(binds, p)
+	}
+	for _, p := range hostPaths {
+		if _, err := os.Stat(p); err == nil {
+			binds = append(binds, p+":"+p)
+		}
+	}
+	r.Docker = d
+	r.Binds = binds
+	r.Env = append(r.Env, "KUBECONFIG="+kubeconfig)
+	r.WorkDir = wd
+	return nil
+}
+
+// binds returns the mounts of the command: the mounts of the toolbox, the
+// directory of the command and the local files and directories it applies.
+func (r *Toolbox) binds(cmd run.Command) []string {
+	binds := slices.Clone(r.Binds)
+	dir := r.WorkDir
+	if cmd.Dir != "" {
+		dir = cmd.Dir
+		binds = addBind(binds, dir)
+	}
+	fields := strings.Fields(cmd.Line)
+	for i := 0; i < len(fields)-1; i++ {
+		if !fileFlags[fields[i]] || strings.Contains(fields[i+1], "://") {
+			continue
+		}
+		p := fields[i+1]
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			p = filepath.Dir(p)
+		}
+		binds = addBind(binds, p)
+	}
+	return binds
+}
+
+// addBind mounts the directory at the same path, unless a mount already
+// includes it.
+func addBind(binds []string, dir string) []string {
+	dir = filepath.Clean(dir)
+	for _, b := range binds {
+		src, _, _ := strings.Cut(b, ":")
+		if dir == src || strings.HasPrefix(dir, src+string(filepath.Separator)) {
+			return binds
+		}
+	}
+	return append(binds, dir+":"+dir)
+}
+
+// kubeconfigPath returns the first file of KUBECONFIG, the kubeconfig kind
+// writes to, or ~/.kube/config.
+func kubeconfigPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.Abs(filepath.SplitList(env)[0])
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Execute runs the command with the shell in a new toolbox container and
+// streams its output. The error of a command that fails is an *ExitError.
+func (r *Toolbox) Execute(ctx context.Context, cmd run.Command, stdout, stderr io.Writer) error {
+	if err := r.setup(); err != nil {
+		return err
+	}
+	// the container does not inherit the environment of the host, only the
+	// variables that are allowed; PATH and HOME are the ones of the image
+	env := []string{}
+	if cmd.Allow != nil {
+		allow := slices.DeleteFunc(slices.Clone(cmd.Allow), func(name string) bool {
+			return name == "PATH" || name == "HOME"
+		})
+		env = (run.Command{Allow: allow}).Environ(os.Environ())
+	}
+	env = append(env, r.Env...)
+	spec := ContainerSpec{
+		Image:      r.Image,
+		Cmd:        []string{cmd.Shell, "-c", cmd.Line},
+		Env:        append(env, cmd.Env...),
+		WorkingDir: r.WorkDir,
+		HostConfig: HostConfig{
+			Binds:       r.binds(cmd),
+			NetworkMode: "host",
+			PidMode:     "host",
+			Privileged:  true,
+		},
+	}
+	if cmd.Dir != "" {
+		spec.WorkingDir = cmd.Dir
+	}
+	id, err := r.Docker.Create(ctx, spec)
+	if errors.Is(err, ErrNoImage) {
+		if err := r.pull(ctx, stderr); err != nil {
+			return err
+		}
+		id, err = r.Docker.Create(ctx, spec)
+	}
+	if err != nil {
+		return err
+	}
+	// the container is removed also when the run is interrupted
+	defer r.Docker.Remove(context.WithoutCancel(ctx), id)
+
+	if err := r.Docker.Start(ctx, id); err != nil {
+		return err
+	}
+	if err := r.Docker.Logs(ctx, id, stdout, stderr); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	code, err := r.Docker.Wait(ctx, id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+	return nil
+}
+
+// Host returns the executor of the steps that run on the host, e.g. the
+// iptables rules.
+func (r *Toolbox) Host() run.Executor {
+	return run.Local{}
+}
+
+// pull pulls the image once.
+func (r *Toolbox) pull(ctx context.Context, log io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pulled {
+		return nil
+	}
+	fmt.Fprintf(log, "pulling toolbox image %s\n", r.Image)
+	if err := r.Docker.Pull(ctx, r.Image); err != nil {
+		return err
+	}
+	r.pulled = true
+	return nil
+}
+
+// ExitError is returned when a command exits with a non zero code.
+type ExitError struct {
+	Code int
+}
+
+func (r *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", r.Code)
+}
+
+// ExitStatus returns the exit code of the command.
+func (r *ExitError) ExitStatus() int {
+	return r.Code
+}
+
+// demux copies the multiplexed stdout and stderr of a container without a
+// tty to the writers. Each frame has an 8 byte header: the stream, 3 zero
+// bytes and the big endian size of the payload.
+func demux(src io.Reader, stdout, stderr io.Writer) error {
+	hdr := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(src, hdr); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		w := stdout
+		if hdr[0] == 2 {
+			w = stderr
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[4:]))
+		if _, err := io.CopyN(w, src, size); err != nil {
+			return err
+		}
+	}
+}
+
+// imageRef splits the image in the name and tag of the pull API.
+func imageRef(image string) (string, string) {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
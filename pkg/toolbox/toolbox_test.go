@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toolbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/exitcode"
+	"github.com/kubenet-dev/kubenetctl/pkg/run"
+)
+
+func TestBinds(t *testing.T) {
+	wd, lab := t.TempDir(), t.TempDir()
+	for _, p := range []string{filepath.Join(wd, "vpc.yaml"), filepath.Join(lab, "manifests", "vpc.yaml")} {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tb := &Toolbox{Binds: []string{"/var/run/docker.sock:/var/run/docker.sock", wd + ":" + wd}, WorkDir: wd}
+
+	tests := map[string]struct {
+		cmd  run.Command
+		want []string
+	}{
+		"mounted file":      {cmd: run.Command{Line: "kubectl apply -f vpc.yaml"}},
+		"url":               {cmd: run.Command{Line: "kubectl apply -f https://example.com/vpc.yaml"}},
+		"missing file":      {cmd: run.Command{Line: "kubectl apply -f /nonexistent/vpc.yaml"}},
+		"directory":         {cmd: run.Command{Line: "kubectl apply -f " + filepath.Join(lab, "manifests")}, want: []string{filepath.Join(lab, "manifests")}},
+		"file of directory": {cmd: run.Command{Line: "kubectl apply -f manifests/vpc.yaml", Dir: lab}, want: []string{lab}},
+		"kustomize":         {cmd: run.Command{Line: "kubectl apply --kustomize " + lab}, want: []string{lab}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := slices.Clone(tb.Binds)
+			for _, p := range tc.want {
+				want = append(want, p+":"+p)
+			}
+			if got := tb.binds(tc.cmd); !slices.Equal(got, want) {
+				t.Errorf("binds() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNoImage(t *testing.T) {
+	err := New("").Execute(context.Background(), run.Command{Shell: "sh", Line: "true"}, nil, nil)
+	if exitcode.For(err) != exitcode.Precondition {
+		t.Errorf("Execute() error = %v, want a precondition error", err)
+	}
+}
+
+func TestImage(t *testing.T) {
+	tests := map[string]string{
+		"1.2.0":  DefaultImage + ":v1.2.0",
+		"v1.2.0": DefaultImage + ":v1.2.0",
+		"0.0.0":  DefaultImage + ":latest",
+		"":       DefaultImage + ":latest",
+	}
+	for version, want := range tests {
+		if got := Image(version); got != want {
+			t.Errorf("Image(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	RuleSyntax    = "syntax"
+	RuleSchema    = "schema"
+	RuleReference = "reference"
+	RuleValue     = "value"
+	RuleDuplicate = "duplicate"
+)
+
+var ruleDescriptions = map[string]string{
+	RuleSyntax:    "The manifest is not valid yaml or not a kubernetes resource.",
+	RuleSchema:    "The resource does not match the CRD schema.",
+	RuleReference: "The resource refers to a node, endpoint or prefix that does not exist.",
+	RuleValue:     "A prefix or range has an invalid value.",
+	RuleDuplicate: "The resource name is used more than once.",
+}
+
+// Finding is a validation error at a location in a manifest file.
+type Finding struct {
+	File    string
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+}
+
+func (r Finding) String() string {
+	col := r.Column
+	if col == 0 {
+		col = 1
+	}
+	return fmt.Sprintf("%s:%d:%d: %s (%s)", r.File, r.Line, col, r.Message, r.Rule)
+}
+
+// Sort orders the findings by file and position.
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// WriteText writes one file:line:column line per finding.
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log for code scanning in
+// CI.
+func WriteSARIF(w io.Writer, version string, findings []Finding) error {
+	type (
+		message struct {
+			Text string `json:"text"`
+		}
+		region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn,omitempty"`
+		}
+		artifactLocation struct {
+			URI string `json:"uri"`
+		}
+		physicalLocation struct {
+			ArtifactLocation artifactLocation `json:"artifactLocation"`
+			Region           region           `json:"region"`
+		}
+		location struct {
+			PhysicalLocation physicalLocation `json:"physicalLocation"`
+		}
+		result struct {
+			RuleID    string     `json:"ruleId"`
+			Level     string     `json:"level"`
+			Message   message    `json:"message"`
+			Locations []location `json:"locations"`
+		}
+		rule struct {
+			ID               string  `json:"id"`
+			ShortDescription message `json:"shortDescription"`
+		}
+		driver struct {
+			Name           string `json:"name"`
+			Version        string `json:"version"`
+			InformationURI string `json:"informationUri"`
+			Rules          []rule `json:"rules"`
+		}
+		tool struct {
+			Driver driver `json:"driver"`
+		}
+		run struct {
+			Tool    tool     `json:"tool"`
+			Results []result `json:"results"`
+		}
+		log struct {
+			Schema  string `json:"$schema"`
+			Version string `json:"version"`
+			Runs    []run  `json:"runs"`
+		}
+	)
+
+	// every rule a finding refers to is declared, in a stable order
+	ids := make([]string, 0, len(ruleDescriptions))
+	for id := range ruleDescriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := []rule{}
+	for _, id := range ids {
+		rules = append(rules, rule{ID: id, ShortDescription: message{Text: ruleDescriptions[id]}})
+	}
+	results := []result{}
+	for _, f := range findings {
+		results = append(results, result{
+			RuleID:  f.Rule,
+			Level:   "error",
+			Message: message{Text: f.Message},
+			Locations: []location{{PhysicalLocation: physicalLocation{
+				ArtifactLocation: artifactLocation{URI: f.File},
+				Region:           region{StartLine: f.Line, StartColumn: f.Column},
+			}}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []run{{
+			Tool: tool{Driver: driver{
+				Name:           "kubenetctl",
+				Version:        version,
+				InformationURI: "https://github.com/kubenet-dev/kubenetctl",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	})
+}
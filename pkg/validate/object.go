@@ -0,0 +1,176 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Object is a kubernetes resource read from a manifest file.
+type Object struct {
+	File       string
+	Line       int
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+	Node       *yaml.Node
+}
+
+func (r *Object) Group() string {
+	group, _, _ := strings.Cut(r.APIVersion, "/")
+	return group
+}
+
+func (r *Object) String() string {
+	return fmt.Sprintf("%s %s", r.Kind, r.Name)
+}
+
+// Decode decodes the object into v.
+func (r *Object) Decode(v any) error {
+	return r.Node.Decode(v)
+}
+
+// Load reads the objects from the files, directories are walked for yaml
+// and json files.
+func Load(paths ...string) ([]*Object, []Finding, error) {
+	objs := []*Object{}
+	findings := []Finding{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			// files given explicitly are read whatever their extension
+			if p != path && !isManifest(p) {
+				return nil
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			o, f := decode(p, b)
+			objs = append(objs, o...)
+			findings = append(findings, f...)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return objs, findings, nil
+}
+
+func isManifest(p string) bool {
+	switch filepath.Ext(p) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func decode(file string, b []byte) ([]*Object, []Finding) {
+	objs := []*Object{}
+	findings := []Finding{}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		doc := &yaml.Node{}
+		err := dec.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			findings = append(findings, Finding{
+				File:    file,
+				Line:    yamlErrorLine(err),
+				Rule:    RuleSyntax,
+				Message: err.Error(),
+			})
+			// the decoder cannot recover from a syntax error
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		node := doc.Content[0]
+		if node.Kind != yaml.MappingNode {
+			findings = append(findings, Finding{
+				File:    file,
+				Line:    node.Line,
+				Column:  node.Column,
+				Rule:    RuleSyntax,
+				Message: "document is not a kubernetes resource",
+			})
+			continue
+		}
+		o := &Object{
+			File:       file,
+			Line:       node.Line,
+			APIVersion: scalar(node, "apiVersion"),
+			Kind:       scalar(node, "kind"),
+			Node:       node,
+		}
+		if md := lookup(node, "metadata"); md != nil {
+			o.Name = scalar(md, "name")
+			o.Namespace = scalar(md, "namespace")
+		}
+		objs = append(objs, o)
+	}
+	return objs, findings
+}
+
+// lookup returns the value of key in a mapping node.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node, key string) string {
+	if v := lookup(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// yamlErrorLine extracts the line number from a yaml error of the format
+// "yaml: line 3: ...".
+func yamlErrorLine(err error) int {
+	var line int
+	if _, scanErr := fmt.Sscanf(err.Error(), "yaml: line %d:", &line); scanErr != nil {
+		return 1
+	}
+	return line
+}
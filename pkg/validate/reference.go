@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"gopkg.in/yaml.v3"
+)
+
+const topologyGroup = "topo.kuid.dev"
+
+// References checks the references between the objects: networks refer to
+// nodes and endpoints of a topology and bridge domains of the same network,
+// network and network config prefixes lie inside an ip index.
+func References(objs []*Object) []Finding {
+	topologies := map[string]*network.Topology{}
+	indexes := []netip.Prefix{}
+	for _, o := range objs {
+		switch {
+		case o.Group() == topologyGroup && o.Kind == "Topology":
+			topologies[o.Name] = topologyFromNode(o.Name, o.Node)
+		case o.Kind == network.IPIndexKind:
+			for _, p := range sequence(lookup(lookup(o.Node, "spec"), "prefixes")) {
+				if prefix, err := netip.ParsePrefix(scalar(p, "prefix")); err == nil {
+					indexes = append(indexes, prefix)
+				}
+			}
+		}
+	}
+
+	findings := []Finding{}
+	errorf := func(o *Object, node *yaml.Node, format string, args ...any) {
+		f := Finding{File: o.File, Line: o.Line, Rule: RuleReference, Message: fmt.Sprintf(format, args...)}
+		if node != nil {
+			f.Line, f.Column = node.Line, node.Column
+		}
+		findings = append(findings, f)
+	}
+
+	checkPrefixes := func(o *Object, prefixes []*yaml.Node) {
+		if len(indexes) == 0 {
+			return
+		}
+		for _, p := range prefixes {
+			prefix, err := netip.ParsePrefix(scalar(p, "prefix"))
+			if err != nil {
+				// reported by the value check
+				continue
+			}
+			if !network.ContainedIn(prefix, indexes) {
+				errorf(o, lookup(p, "prefix"), "prefix %s is not part of an ip index", prefix)
+			}
+		}
+	}
+
+	for _, o := range objs {
+		spec := lookup(o.Node, "spec")
+		switch o.Kind {
+		case network.Kind:
+			bds := map[string]bool{}
+			for _, bd := range sequence(lookup(spec, "bridgeDomains")) {
+				bds[scalar(bd, "name")] = true
+			}
+			topoName := scalar(spec, "topology")
+			topo, ok := topologies[topoName]
+			if !ok && topoName != "" && len(topologies) > 0 {
+				errorf(o, lookup(spec, "topology"), "topology %q is not defined in the validated files", topoName)
+			}
+			for _, rt := range sequence(lookup(spec, "routingTables")) {
+				checkPrefixes(o, sequence(lookup(rt, "prefixes")))
+			}
+			for _, list := range []string{"bridgeDomains", "routingTables"} {
+				for _, entry := range sequence(lookup(spec, list)) {
+					for _, itfce := range sequence(lookup(entry, "interfaces")) {
+						if bd := scalar(itfce, "bridgeDomain"); bd != "" && !bds[bd] {
+							errorf(o, lookup(itfce, "bridgeDomain"), "bridge domain %q is not defined in network %s", bd, o.Name)
+						}
+						node, ep := scalar(itfce, "node"), scalar(itfce, "endpoint")
+						if topo == nil || node == "" {
+							continue
+						}
+						eps, ok := topo.Nodes[node]
+						if !ok {
+							errorf(o, lookup(itfce, "node"), "node %q does not exist in topology %s", node, topo.Name)
+							continue
+						}
+						if ep != "" && !eps[ep] {
+							errorf(o, lookup(itfce, "endpoint"), "endpoint %q does not exist on node %s in topology %s", ep, node, topo.Name)
+						}
+					}
+				}
+			}
+		case network.ConfigKind:
+			checkPrefixes(o, sequence(lookup(spec, "prefixes")))
+		}
+	}
+	return findings
+}
+
+// Duplicates reports objects with the same kind, namespace and name.
+func Duplicates(objs []*Object) []Finding {
+	findings := []Finding{}
+	seen := map[string]*Object{}
+	for _, o := range objs {
+		if o.Name == "" {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%s", o.Group(), o.Kind, o.Namespace, o.Name)
+		if first, ok := seen[key]; ok {
+			findings = append(findings, Finding{
+				File:    o.File,
+				Line:    o.Line,
+				Rule:    RuleDuplicate,
+				Message: fmt.Sprintf("%s is already defined at %s:%d", o, first.File, first.Line),
+			})
+			continue
+		}
+		seen[key] = o
+	}
+	return findings
+}
+
+func topologyFromNode(name string, node *yaml.Node) *network.Topology {
+	t := &network.Topology{Name: name, Nodes: map[string]map[string]bool{}}
+	spec := lookup(node, "spec")
+	for _, n := range sequence(lookup(spec, "nodes")) {
+		t.Nodes[scalar(n, "name")] = map[string]bool{}
+	}
+	for _, l := range sequence(lookup(spec, "links")) {
+		for _, ep := range sequence(lookup(l, "endpoints")) {
+			t.AddEndpoint(scalar(ep, "node"), scalar(ep, "endpoint"))
+		}
+	}
+	return t
+}
+
+func sequence(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
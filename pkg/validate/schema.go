@@ -0,0 +1,266 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// crds are the subsets of the CRDs of the kubenet components the manifests
+// are validated against, see crds/README.md.
+//
+//go:embed crds/*.yaml
+var crds embed.FS
+
+// Schema is the subset of the openAPIV3Schema of a CRD that is validated.
+// Formats of string values are checked by Values.
+type Schema struct {
+	Type                  string             `yaml:"type"`
+	Properties            map[string]*Schema `yaml:"properties"`
+	AdditionalProperties  *Schema            `yaml:"additionalProperties"`
+	Required              []string           `yaml:"required"`
+	Items                 *Schema            `yaml:"items"`
+	Enum                  []string           `yaml:"enum"`
+	Minimum               *float64           `yaml:"minimum"`
+	Maximum               *float64           `yaml:"maximum"`
+	PreserveUnknownFields bool               `yaml:"x-kubernetes-preserve-unknown-fields"`
+}
+
+type crd struct {
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Schema struct {
+				OpenAPIV3Schema *Schema `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// Schemas holds the schemas per apiVersion and kind.
+type Schemas map[string]*Schema
+
+func schemaKey(apiVersion, kind string) string {
+	return apiVersion + "/" + kind
+}
+
+// LoadSchemas reads the bundled CRD schemas.
+func LoadSchemas() (Schemas, error) {
+	entries, err := crds.ReadDir("crds")
+	if err != nil {
+		return nil, err
+	}
+	schemas := Schemas{}
+	for _, e := range entries {
+		b, err := crds.ReadFile(path.Join("crds", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		c := crd{}
+		if err := yaml.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("invalid bundled crd %s: %w", e.Name(), err)
+		}
+		for _, v := range c.Spec.Versions {
+			schemas[schemaKey(c.Spec.Group+"/"+v.Name, c.Spec.Names.Kind)] = v.Schema.OpenAPIV3Schema
+		}
+	}
+	return schemas, nil
+}
+
+// Validate validates the object against its schema. Objects without a
+// bundled schema are ignored, unless they belong to a kuid group.
+func (r Schemas) Validate(o *Object) []Finding {
+	if o.APIVersion == "" || o.Kind == "" {
+		return []Finding{{File: o.File, Line: o.Line, Rule: RuleSchema, Message: "apiVersion and kind are required"}}
+	}
+	s, ok := r[schemaKey(o.APIVersion, o.Kind)]
+	if !ok {
+		if strings.HasSuffix(o.Group(), "kuid.dev") {
+			return []Finding{{File: o.File, Line: o.Line, Rule: RuleSchema, Message: fmt.Sprintf("unknown kind %s in %s", o.Kind, o.APIVersion)}}
+		}
+		return nil
+	}
+	findings := []Finding{}
+	if o.Name == "" {
+		findings = append(findings, Finding{File: o.File, Line: o.Line, Rule: RuleSchema, Message: "metadata.name is required"})
+	}
+	v := &schemaValidator{file: o.File}
+	v.validate(s, o.Node, "")
+	return append(findings, v.findings...)
+}
+
+type schemaValidator struct {
+	file     string
+	findings []Finding
+}
+
+func (r *schemaValidator) errorf(node *yaml.Node, p, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if p != "" {
+		msg = p + ": " + msg
+	}
+	r.findings = append(r.findings, Finding{
+		File:    r.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Rule:    RuleSchema,
+		Message: msg,
+	})
+}
+
+func (r *schemaValidator) validate(s *Schema, node *yaml.Node, p string) {
+	if s == nil {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			r.errorf(node, p, "expected type object")
+			return
+		}
+		r.validateObject(s, node, p)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			r.errorf(node, p, "expected type array")
+			return
+		}
+		for i, item := range node.Content {
+			r.validate(s.Items, item, fmt.Sprintf("%s[%d]", p, i))
+		}
+	case "string", "integer", "number", "boolean":
+		if node.Kind != yaml.ScalarNode {
+			r.errorf(node, p, "expected type %s", s.Type)
+			return
+		}
+		r.validateScalar(s, node, p)
+	}
+}
+
+func (r *schemaValidator) validateObject(s *Schema, node *yaml.Node, p string) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		seen[key.Value] = true
+		fp := joinPath(p, key.Value)
+		if prop, ok := s.Properties[key.Value]; ok {
+			r.validate(prop, value, fp)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			r.validate(s.AdditionalProperties, value, fp)
+			continue
+		}
+		if !s.PreserveUnknownFields {
+			r.errorf(key, p, "unknown field %q%s", key.Value, suggest(key.Value, s.Properties))
+		}
+	}
+	for _, req := range s.Required {
+		if !seen[req] {
+			r.errorf(node, p, "missing required field %q", req)
+		}
+	}
+}
+
+func (r *schemaValidator) validateScalar(s *Schema, node *yaml.Node, p string) {
+	switch s.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil || (s.Type == "integer" && node.Tag != "!!int") {
+			r.errorf(node, p, "expected type %s, got %q", s.Type, node.Value)
+			return
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			r.errorf(node, p, "%s is less than the minimum %v", node.Value, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			r.errorf(node, p, "%s is more than the maximum %v", node.Value, *s.Maximum)
+		}
+	case "boolean":
+		if node.Tag != "!!bool" {
+			r.errorf(node, p, "expected type boolean, got %q", node.Value)
+		}
+	case "string":
+		if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+			r.errorf(node, p, "%q is not one of %v", node.Value, s.Enum)
+		}
+	}
+}
+
+func joinPath(p, key string) string {
+	if p == "" {
+		return key
+	}
+	return p + "." + key
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns a hint for a misspelled field name.
+func suggest(key string, props map[string]*Schema) string {
+	candidates := []string{}
+	for prop := range props {
+		if strings.EqualFold(prop, key) || levenshtein(prop, key) <= 2 {
+			candidates = append(candidates, prop)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return fmt.Sprintf(", did you mean %q?", candidates[0])
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+// Validate loads the manifests from the paths and returns the findings of
+// the schema, value, reference and duplicate checks.
+func Validate(paths ...string) ([]Finding, error) {
+	schemas, err := LoadSchemas()
+	if err != nil {
+		return nil, err
+	}
+	objs, findings, err := Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objs {
+		findings = append(findings, schemas.Validate(o)...)
+	}
+	findings = append(findings, Values(objs)...)
+	findings = append(findings, References(objs)...)
+	findings = append(findings, Duplicates(objs)...)
+	Sort(findings)
+	return findings, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// want is an expected finding, msg is a part of its message.
+type want struct {
+	file string
+	line int
+	rule string
+	msg  string
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		files []string
+		want  []want
+	}{
+		"valid": {
+			files: []string{"topology.yaml", "ipindex.yaml", "network.yaml", "networkconfig.yaml"},
+		},
+		"directory": {
+			files: []string{"topology.yaml", "manifests"},
+		},
+		"syntax": {
+			files: []string{"syntax.yaml", "notresource.yaml"},
+			want: []want{
+				{file: "notresource.yaml", line: 1, rule: RuleSyntax, msg: "not a kubernetes resource"},
+				{file: "syntax.yaml", line: 7, rule: RuleSyntax, msg: "mapping values are not allowed"},
+			},
+		},
+		"schema": {
+			files: []string{"schema.yaml"},
+			want: []want{
+				{file: "schema.yaml", line: 6, rule: RuleSchema, msg: `unknown field "topolgy", did you mean "topology"?`},
+				{file: "schema.yaml", line: 6, rule: RuleSchema, msg: `missing required field "topology"`},
+				{file: "schema.yaml", line: 8, rule: RuleSchema, msg: `spec.bridgeDomains[0]: missing required field "name"`},
+				{file: "schema.yaml", line: 8, rule: RuleSchema, msg: "networkID: 0 is less than the minimum 1"},
+				{file: "schema.yaml", line: 11, rule: RuleSchema, msg: "vlanID: 5000 is more than the maximum 4094"},
+				{file: "schema.yaml", line: 21, rule: RuleSchema, msg: `"ipv4" is not one of`},
+				{file: "schema.yaml", line: 23, rule: RuleSchema, msg: "unknown kind Topologies"},
+			},
+		},
+		"reference": {
+			files: []string{"topology.yaml", "ipindex.yaml", "reference.yaml"},
+			want: []want{
+				{file: "reference.yaml", line: 10, rule: RuleReference, msg: `node "edge03" does not exist in topology kubenet`},
+				{file: "reference.yaml", line: 13, rule: RuleReference, msg: `endpoint "e1-9" does not exist on node edge01`},
+				{file: "reference.yaml", line: 17, rule: RuleReference, msg: "prefix 192.168.0.0/24 is not part of an ip index"},
+				{file: "reference.yaml", line: 19, rule: RuleReference, msg: `bridge domain "vpc2.30" is not defined in network vpc2`},
+				{file: "reference.yaml", line: 26, rule: RuleReference, msg: `topology "lab" is not defined`},
+			},
+		},
+		// without a topology and an ip index in the files the references
+		// are not checked
+		"reference alone": {
+			files: []string{"reference.yaml"},
+			want: []want{
+				{file: "reference.yaml", line: 19, rule: RuleReference, msg: `bridge domain "vpc2.30"`},
+			},
+		},
+		"value": {
+			files: []string{"value.yaml"},
+			want: []want{
+				{file: "value.yaml", line: 8, rule: RuleValue, msg: "invalid prefix"},
+				{file: "value.yaml", line: 11, rule: RuleValue, msg: "start is larger than end"},
+				{file: "value.yaml", line: 14, rule: RuleValue, msg: `invalid range "vni"`},
+			},
+		},
+		"duplicate": {
+			files: []string{"network.yaml", "duplicate.yaml"},
+			want: []want{
+				{file: "duplicate.yaml", line: 1, rule: RuleDuplicate, msg: "Network vpc1 is already defined at " + filepath.Join("testdata", "network.yaml") + ":1"},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			paths := []string{}
+			for _, f := range tc.files {
+				paths = append(paths, filepath.Join("testdata", f))
+			}
+			findings, err := Validate(paths...)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if len(findings) != len(tc.want) {
+				t.Fatalf("Validate() = %d findings, want %d:\n%s", len(findings), len(tc.want), text(t, findings))
+			}
+			for i, w := range tc.want {
+				f := findings[i]
+				if f.File != filepath.Join("testdata", w.file) || f.Line != w.line || f.Rule != w.rule || !strings.Contains(f.Message, w.msg) {
+					t.Errorf("finding %d = %s, want %s:%d %q (%s)", i, f, w.file, w.line, w.msg, w.rule)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	objs, findings, err := Load(filepath.Join("testdata", "manifests"))
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("Load() findings = %v, error = %v", findings, err)
+	}
+	// the README.txt in the directory is not a manifest
+	got := []string{}
+	for _, o := range objs {
+		got = append(got, o.Group()+" "+o.String())
+	}
+	if want := "ipam.be.kuid.dev IPIndex default,network.app.kuid.dev Network vpc1"; strings.Join(got, ",") != want {
+		t.Errorf("Load() = %v, want %s", got, want)
+	}
+
+	if _, _, err := Load(filepath.Join("testdata", "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
+
+// writerFindings are the findings of the writer tests, one of each rule.
+var writerFindings = []Finding{
+	{File: "network.yaml", Line: 6, Column: 3, Rule: RuleSchema, Message: `spec: unknown field "topolgy"`},
+	{File: "network.yaml", Line: 8, Column: 13, Rule: RuleValue, Message: "invalid prefix"},
+	{File: "network.yaml", Line: 19, Column: 21, Rule: RuleReference, Message: `bridge domain "vpc2.30" is not defined`},
+	{File: "syntax.yaml", Line: 7, Rule: RuleSyntax, Message: "mapping values are not allowed"},
+}
+
+func TestWriteText(t *testing.T) {
+	want := `network.yaml:6:3: spec: unknown field "topolgy" (schema)
+network.yaml:8:13: invalid prefix (value)
+network.yaml:19:21: bridge domain "vpc2.30" is not defined (reference)
+syntax.yaml:7:1: mapping values are not allowed (syntax)
+`
+	if got := text(t, writerFindings); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteSARIF(buf, "v1.0.0", writerFindings); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	golden := filepath.Join("testdata", "findings.sarif")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(b) {
+		t.Errorf("WriteSARIF() =\n%s\nwant\n%s", buf, b)
+	}
+
+	// every rule of a result is declared
+	log := struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct{ RuleID string }
+		}
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]bool{}
+	for _, r := range log.Runs[0].Tool.Driver.Rules {
+		declared[r.ID] = true
+	}
+	for id := range ruleDescriptions {
+		if !declared[id] {
+			t.Errorf("rule %s is not declared", id)
+		}
+	}
+	for _, r := range log.Runs[0].Results {
+		if !declared[r.RuleID] {
+			t.Errorf("result rule %s is not declared", r.RuleID)
+		}
+	}
+}
+
+func text(t *testing.T, findings []Finding) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := WriteText(buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/kubenet-dev/kubenetctl/pkg/network"
+	"gopkg.in/yaml.v3"
+)
+
+// Values checks the string values the kuid controllers parse, which the CRD
+// schemas only describe as strings: prefixes and AS and VNI pools.
+func Values(objs []*Object) []Finding {
+	findings := []Finding{}
+	errorf := func(o *Object, node *yaml.Node, format string, args ...any) {
+		findings = append(findings, Finding{
+			File:    o.File,
+			Line:    node.Line,
+			Column:  node.Column,
+			Rule:    RuleValue,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	checkPrefixes := func(o *Object, prefixes []*yaml.Node) {
+		for _, p := range prefixes {
+			node := lookup(p, "prefix")
+			if node == nil || node.Kind != yaml.ScalarNode {
+				continue
+			}
+			if _, err := netip.ParsePrefix(node.Value); err != nil {
+				errorf(o, node, "invalid prefix: %s", err)
+			}
+		}
+	}
+	checkRange := func(o *Object, node *yaml.Node) {
+		if node == nil || node.Kind != yaml.ScalarNode {
+			return
+		}
+		if _, err := network.ParseRange(node.Value); err != nil {
+			errorf(o, node, "%s", err)
+		}
+	}
+
+	for _, o := range objs {
+		spec := lookup(o.Node, "spec")
+		switch o.Kind {
+		case network.IPIndexKind:
+			checkPrefixes(o, sequence(lookup(spec, "prefixes")))
+		case network.Kind:
+			for _, rt := range sequence(lookup(spec, "routingTables")) {
+				checkPrefixes(o, sequence(lookup(rt, "prefixes")))
+			}
+		case network.ConfigKind:
+			checkPrefixes(o, sequence(lookup(spec, "prefixes")))
+			checkRange(o, lookup(lookup(lookup(spec, "protocols"), "ebgp"), "asPool"))
+			checkRange(o, lookup(lookup(lookup(spec, "encapsulation"), "vxlan"), "vniPool"))
+		}
+	}
+	return findings
+}